@@ -25,6 +25,7 @@ import (
 	"github.com/openshift/library-go/pkg/serviceability"
 	"github.com/openshift/openshift-tests-private/pkg/monitor"
 	testginkgo "github.com/openshift/openshift-tests-private/pkg/test/ginkgo"
+	"github.com/openshift/openshift-tests-private/test/extended"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 	exutilcloud "github.com/openshift/openshift-tests-private/test/extended/util/cloud"
 
@@ -57,6 +58,10 @@ func main() {
 		newRunUpgradeCommand(),
 		newRunTestCommand(),
 		newRunMonitorCommand(),
+		newListCommand(),
+		newHistoryCommand(),
+		newCompareCommand(),
+		newReportCommand(),
 	)
 
 	pflag.CommandLine = pflag.NewFlagSet("empty", pflag.ExitOnError)
@@ -116,6 +121,35 @@ func newRunCommand() *cobra.Command {
 		command with the --file argument. You may also pipe a list of test names, one per line, on
 		standard input by passing "-f -".
 
+		Tests may also be selected by the metadata in their titles with --importance, --author,
+		--case-id and --exclude-prefix. These filters are combined with --run. Titles that do not
+		follow the naming rule are reported as warnings by --dry-run.
+
+		A test named with [Timeout:<duration>], for example [Timeout:90m], runs with that timeout
+		instead of the suite timeout. Tests named with the same [Exclusive:<resource>] label, for
+		example [Exclusive:ingress-default], never run at the same time. A test may have several.
+
+		Tests that cannot run on the cluster are excluded before the suite starts. The IP stack,
+		topology, node architectures, proxy, disconnected install, FIPS mode, feature set and enabled
+		optional capabilities of the cluster are detected, and tests labelled for example
+		[Skipped:SNO], [Skipped:Disconnected], [Skipped:ipv6], [Skipped:arm64], [Skipped:FIPS] or
+		[Skipped:NoCapability/<name>], as well as ConnectedOnly- titles on disconnected clusters, are
+		not run, and are listed on standard error. The cluster is not queried with --dry-run, which
+		lists every test of the suite.
+
+		Additional suites may be defined in YAML with --suite-file, either a single file or a
+		directory of .yaml files. A suite defined in a file replaces a built-in suite of the same name.
+
+		To run the same suite against several clusters at once, pass their kubeconfig files with
+		--kubeconfigs. Each cluster is monitored and runs tests in its own worker pool, and its report
+		is written to a directory of --junit-dir named after its platform. The aggregated report holds
+		a suite per cluster, and a matrix of the tests that failed on any cluster is printed at the end.
+
+		To hunt for flakes, --repeat-until-failure and --soak run the selected tests again and again,
+		until one of them fails or for the given duration. The pass rate of each test is printed at the
+		end with a 95% confidence interval, and only the output of the first --soak-failure-outputs
+		failures of each test is kept. Failed tests are not retried.
+
 		`) + testginkgo.SuitesString(opt.Suites, "\n\nAvailable test suites:\n\n"),
 
 		SilenceUsage:  true,
@@ -129,11 +163,15 @@ func newRunCommand() *cobra.Command {
 				e2e.AfterReadingAllFlags(exutil.TestContext)
 				e2e.TestContext.DumpLogsOnFailure = true
 				exutil.TestContext.DumpLogsOnFailure = true
+				opt.ClusterSkips = exutil.ClusterSkipReason
+				opt.Owners = testginkgo.NewOwnersIndex(extended.OwnersFiles)
 				return opt.Run(args)
 			})
 		},
 	}
 	bindOptions(opt, cmd.Flags())
+	cmd.Flags().StringVar(&opt.SuiteFile, "suite-file", opt.SuiteFile, "A YAML suite definition, or a directory of them, to add to the available suites.")
+	cmd.Flags().StringSliceVar(&opt.Kubeconfigs, "kubeconfigs", opt.Kubeconfigs, "Run the suite against each of these clusters at the same time and aggregate the results.")
 	return cmd
 }
 
@@ -153,13 +191,34 @@ func newRunUpgradeCommand() *cobra.Command {
 		If you specify the --dry-run argument, the actions the suite will take will be printed to the
 		output.
 
+		Pass a comma-separated list to --to-image or --to-version to upgrade through each version in
+		order while the invariant tests run across all of the upgrades, which are reported as a test
+		case per hop. With --to-version alone, or --channel, the update is picked from the available
+		updates of the cluster the way a customer would pick it, and is not forced.
+
+		Tests labeled [PreChkUpgrade] run before the upgrade and tests labeled [PstChkUpgrade] after it.
+		A pre-upgrade check passes state to its post-upgrade check with exutil.SetUpgradeState, which
+		is stored in --junit-dir/upgrade-state unless TEST_UPGRADE_STATE_DIR is set.
+
 		Supported options:
 
 		* abort-at=NUMBER - Set to a number between 0 and 100 to control the percent of operators
-		at which to stop the current upgrade and roll back to the current version.
+		at which to stop the current upgrade and roll back to the current version. Set to
+		'operator:NAME' to roll back once the named cluster operator has upgraded, or to
+		'co:NAME:CONDITION=STATUS' (e.g. co:network:Progressing=True) to roll back once the named
+		cluster operator reports the condition. The rollback is reported as its own test case.
 		* disrupt-reboot=POLICY - During upgrades, periodically reboot master nodes. If set to 'graceful'
 		the reboot will allow the node to shut down services in an orderly fashion. If set to 'force' the
 		machine will terminate immediately without clean shutdown.
+		* eus=true - Upgrade as a customer on an EUS release does: pause the worker pools, upgrade the
+		control plane through the two versions given to --to-image or --to-version, then unpause the
+		pools and wait for them to update.
+		* tests=REGEX - Run the tests whose name matches the regular expression during the upgrade,
+		chosen from the Kubernetes tests and the OpenShift tests: a route through the default ingress
+		controller, an image stream import and pull from the integrated registry, a deployment config
+		rollout, an OAuth token login and a persistent volume claim that keeps its data. Separate
+		alternatives with '|', e.g. tests=route-upgrade|oauth-token-upgrade. By default only the
+		Kubernetes tests run.
 
 		`) + testginkgo.SuitesString(opt.Suites, "\n\nAvailable upgrade suites:\n\n"),
 
@@ -167,8 +226,8 @@ func newRunUpgradeCommand() *cobra.Command {
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return mirrorToFile(opt, func() error {
-				if len(upgradeOpt.ToImage) == 0 {
-					return fmt.Errorf("--to-image must be specified to run an upgrade test")
+				if len(upgradeOpt.ToImage) == 0 && len(upgradeOpt.ToVersion) == 0 && len(upgradeOpt.Channel) == 0 {
+					return fmt.Errorf("--to-image, --to-version or --channel must be specified to run an upgrade test")
 				}
 
 				if len(args) > 0 {
@@ -192,6 +251,8 @@ func newRunUpgradeCommand() *cobra.Command {
 				e2e.AfterReadingAllFlags(exutil.TestContext)
 				e2e.TestContext.DumpLogsOnFailure = true
 				exutil.TestContext.DumpLogsOnFailure = true
+				opt.ClusterSkips = exutil.ClusterSkipReason
+				opt.Owners = testginkgo.NewOwnersIndex(extended.OwnersFiles)
 				return opt.Run(args)
 			})
 		},
@@ -237,6 +298,151 @@ func newRunTestCommand() *cobra.Command {
 	return cmd
 }
 
+func newListCommand() *cobra.Command {
+	listOpt := &testginkgo.ListOptions{
+		Suites: staticSuites,
+		Owners: testginkgo.NewOwnersIndex(extended.OwnersFiles),
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list [SUITE]",
+		Short: "List the tests known to this binary",
+		Long: templates.LongDesc(`
+		Print the inventory of tests
+
+		Each test is printed with the suites that select it, its code location, the sig and subteam
+		of its Describe block, the labels in its name, the metadata parsed from its title and the
+		approvers and reviewers of the nearest OWNERS file. If a suite is given only the tests of
+		that suite are printed.
+		`) + testginkgo.SuitesString(listOpt.Suites, "\n\nAvailable test suites:\n\n"),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := initProvider(os.Getenv("TEST_PROVIDER"), true); err != nil {
+				return err
+			}
+			e2e.AfterReadingAllFlags(exutil.TestContext)
+			return listOpt.Run(args)
+		},
+	}
+	cmd.Flags().StringVarP(&listOpt.Output, "output", "o", "json", "The output format, one of json, yaml or csv.")
+	cmd.Flags().StringVar(&listOpt.SuiteFile, "suite-file", listOpt.SuiteFile, "A YAML suite definition, or a directory of them, to add to the available suites.")
+	return cmd
+}
+
+func newHistoryCommand() *cobra.Command {
+	historyOpt := &testginkgo.HistoryOptions{
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Report statistics from previously recorded runs",
+		Long: templates.LongDesc(`
+		Report pass rates, flake rates and durations of recorded runs
+
+		Runs are recorded with run --history-db. For each test or sig.subteam the pass, flake and fail
+		rates, the 50th, 90th and 99th percentile durations and the start of the current streak of
+		failures are printed. Skipped results are not counted.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return historyOpt.Run(args)
+		},
+	}
+	cmd.Flags().StringVar(&historyOpt.DBPath, "history-db", historyOpt.DBPath, "The SQLite database runs were recorded to.")
+	cmd.Flags().StringVar(&historyOpt.GroupBy, "by", "test", "Group results by test or subteam.")
+	cmd.Flags().StringVar(&historyOpt.Regex, "run", historyOpt.Regex, "Regular expression of tests to report on.")
+	cmd.Flags().StringVarP(&historyOpt.Output, "output", "o", "text", "The output format, one of text or json.")
+	return cmd
+}
+
+func newCompareCommand() *cobra.Command {
+	compareOpt := &testginkgo.CompareOptions{
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "compare OLD_JUNIT_FILE NEW_JUNIT_FILE",
+		Short: "Report what changed between two runs",
+		Long: templates.LongDesc(`
+		Compare the JUnit reports written by two runs
+
+		The tests that fail in the new run but did not in the old one, that pass again, that are
+		now skipped, or that were not run at all are printed, grouped by subteam. Passing tests
+		whose duration grew by more than --duration-threshold and --min-duration are reported as
+		slower. Tests that failed and then passed are reported as flakes.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return compareOpt.Run(args)
+		},
+	}
+	cmd.Flags().Float64Var(&compareOpt.DurationThreshold, "duration-threshold", 0.5, "Report passing tests whose duration grew by more than this fraction, for example 0.5 for 50%.")
+	cmd.Flags().DurationVar(&compareOpt.MinimumDuration, "min-duration", 30*time.Second, "Ignore duration increases smaller than this.")
+	cmd.Flags().StringVarP(&compareOpt.Output, "output", "o", "text", "The output format, one of text, markdown or json.")
+	return cmd
+}
+
+func newReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Publish the results of a run",
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(newReportUploadCommand())
+	return cmd
+}
+
+func newReportUploadCommand() *cobra.Command {
+	uploadOpt := &testginkgo.ReportUploadOptions{
+		Project: "ocp",
+		Token:   os.Getenv("REPORTPORTAL_TOKEN"),
+		Out:     os.Stdout,
+		ErrOut:  os.Stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "upload JUNIT_FILE...",
+		Short: "Upload JUnit results to ReportPortal",
+		Long: templates.LongDesc(`
+		Upload the JUnit reports written by run to ReportPortal as a new launch
+
+		Tests are grouped into an item per sig containing an item per subteam. Each test is
+		tagged with the importance, author and case IDs from its title, and the output of
+		failing tests is attached to it. The monitor timeline of the run is attached to the
+		launch. Tests that failed and then passed are reported as passing flakes.
+
+		The API token is read from the REPORTPORTAL_TOKEN environment variable unless --token
+		is specified.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return uploadOpt.Run(args)
+		},
+	}
+	cmd.Flags().StringVar(&uploadOpt.ReportPortalURL, "reportportal-url", uploadOpt.ReportPortalURL, "The ReportPortal API endpoint, for example https://reportportal.example.com/api.")
+	cmd.Flags().StringVar(&uploadOpt.Project, "project", uploadOpt.Project, "The ReportPortal project to create the launch in.")
+	cmd.Flags().StringVar(&uploadOpt.Token, "token", uploadOpt.Token, "The ReportPortal API token.")
+	cmd.Flags().StringVar(&uploadOpt.Launch, "launch", uploadOpt.Launch, "The name of the launch to create.")
+	cmd.Flags().StringVar(&uploadOpt.Description, "description", uploadOpt.Description, "A description of the launch.")
+	cmd.Flags().StringSliceVar(&uploadOpt.Attributes, "attribute", uploadOpt.Attributes, "Attributes of the launch as key:value, may be specified multiple times.")
+	return cmd
+}
+
 // mirrorToFile ensures a copy of all output goes to the provided OutFile, including
 // any error returned from fn. The function returns fn() or any error encountered while
 // attempting to open the file.
@@ -265,14 +471,34 @@ func mirrorToFile(opt *testginkgo.Options, fn func() error) error {
 func bindOptions(opt *testginkgo.Options, flags *pflag.FlagSet) {
 	flags.BoolVar(&opt.DryRun, "dry-run", opt.DryRun, "Print the tests to run without executing them.")
 	flags.BoolVar(&opt.PrintCommands, "print-commands", opt.PrintCommands, "Print the sub-commands that would be executed instead.")
-	flags.StringVar(&opt.JUnitDir, "junit-dir", opt.JUnitDir, "The directory to write test reports to.")
+	flags.StringVar(&opt.JUnitDir, "junit-dir", opt.JUnitDir, "The directory to write test reports to. Failed tests also write a diagnostic bundle to artifacts/<case-id> in this directory.")
+	flags.BoolVar(&opt.JUnitBySubteam, "junit-by-subteam", opt.JUnitBySubteam, "Write the tests of each subteam to a nested test suite of the JUnit report.")
+	flags.StringVar(&opt.QuarantineFile, "quarantine", opt.QuarantineFile, "A YAML file of tests, by name or case ID, whose failures are reported as flakes and do not fail the suite. Each entry needs an owner, a reason and an expiry date.")
+	flags.StringVar(&opt.Preflight, "preflight", opt.Preflight, "Check that cluster operators are available and not degraded, nodes are ready, machine config pools are not updating and the API responds before starting tests. Report the result as a test, or stop the run if the cluster is unhealthy with --preflight=abort.")
+	flags.Lookup("preflight").NoOptDefVal = testginkgo.PreflightReport
+	flags.StringVar(&opt.HistoryDB, "history-db", opt.HistoryDB, "Record the cluster version, platform and result of every test to this SQLite database.")
+	flags.BoolVar(&opt.Resume, "resume", opt.Resume, "Skip tests that passed or were skipped in the checkpoint of a previous run in --junit-dir and merge their results into the report.")
 	flags.StringVar(&opt.Provider, "provider", opt.Provider, "The cluster infrastructure provider. Will automatically default to the correct value.")
 	flags.StringVarP(&opt.TestFile, "file", "f", opt.TestFile, "Create a suite from the newline-delimited test names in this file.")
 	flags.StringVar(&opt.Regex, "run", opt.Regex, "Regular expression of tests to run.")
+	flags.StringSliceVar(&opt.Filter.Importance, "importance", opt.Filter.Importance, "Only run tests with one of these importance values (Critical, High, Medium, Low).")
+	flags.StringSliceVar(&opt.Filter.Authors, "author", opt.Filter.Authors, "Only run tests written by one of these authors.")
+	flags.StringVar(&opt.Owner, "owner", opt.Owner, "Only run tests this GitHub id is an approver or reviewer of in the nearest OWNERS file.")
+	flags.StringSliceVar(&opt.Filter.CaseIDs, "case-id", opt.Filter.CaseIDs, "Only run tests covering one of these test case IDs.")
+	flags.StringSliceVar(&opt.Filter.ExcludePrefixes, "exclude-prefix", opt.Filter.ExcludePrefixes, "Skip tests whose title carries one of these prefixes, such as Longduration or NonPreRelease.")
 	flags.StringVarP(&opt.OutFile, "output-file", "o", opt.OutFile, "Write all test output to this file.")
+	flags.BoolVar(&opt.RepeatUntilFailure, "repeat-until-failure", opt.RepeatUntilFailure, "Run the tests again and again until one of them fails, then print the pass rate of each test.")
+	flags.DurationVar(&opt.Soak, "soak", opt.Soak, "Run the tests again and again for this long, then print the pass rate of each test. May be combined with --repeat-until-failure to stop at the first failure.")
+	flags.IntVar(&opt.SoakFailureOutputs, "soak-failure-outputs", 3, "The number of failures of each test whose output is kept with --repeat-until-failure or --soak.")
 	flags.IntVar(&opt.Count, "count", opt.Count, "Run each test a specified number of times. Defaults to 1 or the suite's preferred value.")
-	flags.DurationVar(&opt.Timeout, "timeout", opt.Timeout, "Set the maximum time a test can run before being aborted. This is read from the suite by default, but will be 10 minutes otherwise.")
+	flags.DurationVar(&opt.Timeout, "timeout", opt.Timeout, "Set the maximum time a test can run before being aborted. This is read from the suite or a [Timeout:<duration>] label of the test by default, but will be 10 minutes otherwise.")
+	flags.DurationVar(&opt.SuiteTimeout, "suite-timeout", opt.SuiteTimeout, "Stop starting tests once the suite has run for this long. Running tests are allowed to finish and tests that were not started are reported as skipped.")
+	flags.StringVar(&opt.OutputFormat, "output-format", opt.OutputFormat, "The format of the test lifecycle output. 'text' is human readable, 'ndjson' also writes one JSON object per event.")
+	flags.StringVar(&opt.EventsFile, "events-file", opt.EventsFile, "Write the ndjson events to this file instead of stdout. When events are written to stdout the text output is written to stderr.")
 	flags.BoolVar(&opt.IncludeSuccessOutput, "include-success", opt.IncludeSuccessOutput, "Print output from successful tests.")
+	flags.IntVar(&opt.ShardCount, "shard-count", opt.ShardCount, "Split the suite into this many shards and only run the one selected by --shard-index. [Serial] tests always run on shard 0.")
+	flags.IntVar(&opt.ShardIndex, "shard-index", opt.ShardIndex, "The zero-based index of the shard to run when --shard-count is set.")
+	flags.StringVar(&opt.DurationsFile, "durations", opt.DurationsFile, "Test durations from a previous run, as a JUnit report or a JSON object of test name to seconds. Used to balance shards and to start the longest tests first.")
 	flags.IntVar(&opt.Parallelism, "max-parallel-tests", opt.Parallelism, "Maximum number of tests running in parallel. 0 defaults to test suite recommended value, which is different in each suite.")
 }
 
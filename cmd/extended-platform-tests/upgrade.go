@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -28,31 +29,63 @@ var upgradeSuites = []*ginkgo.TestSuite{
 			return strings.Contains(name, "[Feature:ClusterUpgrade]") && strings.Contains(name, "[Suite:openshift]")
 		},
 
-		Init: func(opt map[string]string) error {
-			for k, v := range opt {
-				switch k {
-				case "abort-at":
-					if err := upgrade.SetUpgradeAbortAt(v); err != nil {
-						return err
-					}
-				case "disrupt-reboot":
-					if err := upgrade.SetUpgradeDisruptReboot(v); err != nil {
-						return err
-					}
-				default:
-					return fmt.Errorf("unrecognized upgrade option: %s", k)
-				}
-			}
-			return filterUpgrade(upgrade.AllTests(), func(name string) bool { return true })
+		Init:        initUpgradeSuite,
+		TestTimeout: 120 * time.Minute,
+	},
+	{
+		Name: "pre-post-check",
+		Description: templates.LongDesc(`
+		Run the pre-upgrade checks, upgrade the cluster, then run the post-upgrade checks. Checks are
+		labeled [PreChkUpgrade] or [PstChkUpgrade], and a post-upgrade check is paired with the
+		pre-upgrade check of the same sig and first case ID. It is skipped if its pre-upgrade check
+		did not pass, and each pair is reported as one test.
+		`),
+		Matches: func(name string) bool {
+			return (strings.Contains(name, "[Feature:ClusterUpgrade]") && strings.Contains(name, "[Suite:openshift]")) || ginkgo.IsUpgradeCheck(name)
 		},
+
+		Init:        initUpgradeSuite,
 		TestTimeout: 120 * time.Minute,
 	},
 }
 
+func initUpgradeSuite(opt map[string]string) error {
+	tests := upgrade.AllTests()
+	match := func(name string) bool { return true }
+	for k, v := range opt {
+		switch k {
+		case "tests":
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return fmt.Errorf("tests must be a regular expression: %v", err)
+			}
+			tests = append(upgrade.AllTests(), upgrade.OpenShiftTests()...)
+			match = re.MatchString
+		case "abort-at":
+			if err := upgrade.SetUpgradeAbortAt(v); err != nil {
+				return err
+			}
+		case "eus":
+			if err := upgrade.SetUpgradeEUS(v); err != nil {
+				return err
+			}
+		case "disrupt-reboot":
+			if err := upgrade.SetUpgradeDisruptReboot(v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unrecognized upgrade option: %s", k)
+		}
+	}
+	return filterUpgrade(tests, match)
+}
+
 type UpgradeOptions struct {
-	Suite    string
-	ToImage  string
-	JUnitDir string
+	Suite     string
+	ToImage   string
+	ToVersion string
+	Channel   string
+	JUnitDir  string
 
 	TestOptions []string
 }
@@ -94,15 +127,26 @@ func initUpgrade(value string) error {
 	}
 	for _, suite := range upgradeSuites {
 		if suite.Name == opt.Suite {
-			gcp.SetUpgradeTarget("")
+			gcp.SetUpgradeTarget(opt.ToVersion)
 			gcp.SetUpgradeImage(opt.ToImage)
+			upgrade.SetUpgradeChannel(opt.Channel)
 			exutil.TestContext.ReportDir = opt.JUnitDir
 			o, err := opt.OptionsMap()
 			if err != nil {
 				return err
 			}
 			if suite.Init != nil {
-				return suite.Init(o)
+				if err := suite.Init(o); err != nil {
+					return err
+				}
+			}
+			// the upgrade test runs every hop, so a long upgrade needs more time than the suite default
+			timeout, err := upgrade.TestTimeout(opt.ToVersion, opt.ToImage)
+			if err != nil {
+				return err
+			}
+			if timeout > suite.TestTimeout {
+				suite.TestTimeout = timeout
 			}
 			return nil
 		}
@@ -122,6 +166,8 @@ func filterUpgrade(tests []upgrades.Test, match func(string) bool) error {
 }
 
 func bindUpgradeOptions(opt *UpgradeOptions, flags *pflag.FlagSet) {
-	flags.StringVar(&opt.ToImage, "to-image", opt.ToImage, "Specify the image to test an upgrade to.")
+	flags.StringVar(&opt.ToImage, "to-image", opt.ToImage, "Specify the image to test an upgrade to, or a comma-separated list of images to upgrade through in order.")
+	flags.StringVar(&opt.ToVersion, "to-version", opt.ToVersion, "Specify the version to test an upgrade to, or a comma-separated list of versions to upgrade through in order. Versions without an image are picked from the available updates of the cluster.")
+	flags.StringVar(&opt.Channel, "channel", opt.Channel, "Switch the cluster to this channel before picking updates from its available updates. Without --to-image or --to-version, upgrade to the latest update in the channel.")
 	flags.StringSliceVar(&opt.TestOptions, "options", opt.TestOptions, "A set of KEY=VALUE options to control the test. See the help text.")
 }
@@ -12,6 +12,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -25,8 +26,16 @@ import (
 // Start begins monitoring the cluster referenced by the default kube configuration until
 // context is finished.
 func Start(ctx context.Context) (*Monitor, error) {
+	return StartWithKubeconfig(ctx, "")
+}
+
+// StartWithKubeconfig begins monitoring the cluster referenced by the kubeconfig file
+// until context is finished. An empty path uses the default kube configuration.
+func StartWithKubeconfig(ctx context.Context, kubeconfig string) (*Monitor, error) {
 	m := NewMonitor()
-	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
 	clusterConfig, err := cfg.ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("could not load client configuration: %v", err)
@@ -40,13 +49,28 @@ func Start(ctx context.Context) (*Monitor, error) {
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := startAPIMonitoring(ctx, m, clusterConfig); err != nil {
 		return nil, err
 	}
 	startPodMonitoring(ctx, m, client)
-	startNodeMonitoring(ctx, m, client)
+	nodeInformer := startNodeMonitoring(ctx, m, client)
 	startEventMonitoring(ctx, m, client)
-	startClusterOperatorMonitoring(ctx, m, configClient)
+	coInformer := startClusterOperatorMonitoring(ctx, m, configClient)
+	poolInformer := startMachineConfigPoolMonitoring(ctx, m, dynamicClient)
+	m.health = &healthSources{
+		api: func() error {
+			_, err := client.CoreV1().Namespaces().Get("kube-system", metav1.GetOptions{})
+			return err
+		},
+		clusterOperators: coInformer,
+		nodes:            nodeInformer,
+		pools:            poolInformer,
+	}
 
 	m.StartSampling(ctx)
 	return m, nil
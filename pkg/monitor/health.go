@@ -0,0 +1,192 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var machineConfigPoolResource = schema.GroupVersionResource{
+	Group:    "machineconfiguration.openshift.io",
+	Version:  "v1",
+	Resource: "machineconfigpools",
+}
+
+// healthSources are the informers and checks started by Start that describe
+// whether the cluster is healthy.
+type healthSources struct {
+	api              func() error
+	clusterOperators cache.SharedIndexInformer
+	nodes            cache.SharedIndexInformer
+	pools            cache.SharedIndexInformer
+}
+
+// CheckHealth returns the reasons the cluster is not healthy: the API does not
+// respond, a cluster operator is not Available or is Degraded, a node is not
+// Ready, or a machine config pool is updating. It waits until ctx is done for
+// the informers started by Start to sync. An empty result means the cluster is
+// healthy.
+func (m *Monitor) CheckHealth(ctx context.Context) []string {
+	if m.health == nil {
+		return []string{"the monitor is not watching a cluster"}
+	}
+	var problems []string
+	if err := m.health.api(); err != nil {
+		problems = append(problems, fmt.Sprintf("kube-apiserver is not responding to GET requests: %v", err))
+	}
+	check := func(resource string, informer cache.SharedIndexInformer, fn func([]interface{}) []string) {
+		if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			problems = append(problems, fmt.Sprintf("could not list %s", resource))
+			return
+		}
+		problems = append(problems, fn(informer.GetStore().List())...)
+	}
+	check("clusteroperators", m.health.clusterOperators, clusterOperatorProblems)
+	check("nodes", m.health.nodes, nodeProblems)
+	check("machineconfigpools", m.health.pools, machineConfigPoolProblems)
+	return problems
+}
+
+func clusterOperatorProblems(objs []interface{}) []string {
+	var problems []string
+	for _, obj := range objs {
+		co, ok := obj.(*configv1.ClusterOperator)
+		if !ok {
+			continue
+		}
+		if c := findOperatorStatusCondition(co.Status.Conditions, configv1.OperatorAvailable); c == nil || c.Status != configv1.ConditionTrue {
+			problems = append(problems, fmt.Sprintf("%s is not Available%s", locateClusterOperator(co), conditionReason(c)))
+		}
+		if c := findOperatorStatusCondition(co.Status.Conditions, configv1.OperatorDegraded); c != nil && c.Status == configv1.ConditionTrue {
+			problems = append(problems, fmt.Sprintf("%s is Degraded%s", locateClusterOperator(co), conditionReason(c)))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func conditionReason(c *configv1.ClusterOperatorStatusCondition) string {
+	switch {
+	case c == nil:
+		return ""
+	case len(c.Reason) > 0 && len(c.Message) > 0:
+		return fmt.Sprintf(": %s: %s", c.Reason, c.Message)
+	case len(c.Message) > 0:
+		return fmt.Sprintf(": %s", c.Message)
+	}
+	return ""
+}
+
+func nodeProblems(objs []interface{}) []string {
+	var problems []string
+	for _, obj := range objs {
+		node, ok := obj.(*corev1.Node)
+		if !ok {
+			continue
+		}
+		if c := findNodeCondition(node.Status.Conditions, corev1.NodeReady, 0); c == nil || c.Status != corev1.ConditionTrue {
+			problems = append(problems, fmt.Sprintf("%s is not Ready", locateNode(node)))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func machineConfigPoolProblems(objs []interface{}) []string {
+	var problems []string
+	for _, obj := range objs {
+		pool, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if machineConfigPoolCondition(pool, "Updating") == "True" {
+			problems = append(problems, fmt.Sprintf("%s is updating", locateMachineConfigPool(pool)))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// machineConfigPoolCondition returns the status of the condition of the provided
+// type, or an empty string.
+// TODO: drop this when MCO types are in openshift/api and we can use the typed client directly
+func machineConfigPoolCondition(pool *unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(pool.Object, "status", "conditions")
+	for _, obj := range conditions {
+		condition, ok := obj.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _, _ := unstructured.NestedString(condition, "type"); t != conditionType {
+			continue
+		}
+		status, _, _ := unstructured.NestedString(condition, "status")
+		return status
+	}
+	return ""
+}
+
+func locateMachineConfigPool(pool *unstructured.Unstructured) string {
+	return fmt.Sprintf("machineconfigpool/%s", pool.GetName())
+}
+
+func startMachineConfigPoolMonitoring(ctx context.Context, m Recorder, client dynamic.Interface) cache.SharedIndexInformer {
+	poolInformer := dynamicinformer.NewFilteredDynamicInformer(client, machineConfigPoolResource, "", time.Hour, cache.Indexers{}, nil).Informer()
+	poolInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, obj interface{}) {
+				pool, ok := obj.(*unstructured.Unstructured)
+				if !ok {
+					return
+				}
+				oldPool, ok := old.(*unstructured.Unstructured)
+				if !ok {
+					return
+				}
+				for _, conditionType := range []string{"Updating", "Degraded"} {
+					status := machineConfigPoolCondition(pool, conditionType)
+					if status == machineConfigPoolCondition(oldPool, conditionType) {
+						continue
+					}
+					level := Info
+					if conditionType == "Degraded" && status == "True" {
+						level = Error
+					}
+					m.Record(Condition{
+						Level:   level,
+						Locator: locateMachineConfigPool(pool),
+						Message: fmt.Sprintf("changed %s to %s", conditionType, status),
+					})
+				}
+			},
+		},
+	)
+	go poolInformer.Run(ctx.Done())
+	return poolInformer
+}
+
+// ClusterOperators returns the cluster operators last observed by the monitor, or
+// nil if it is not watching a cluster.
+func (m *Monitor) ClusterOperators() []*configv1.ClusterOperator {
+	if m.health == nil {
+		return nil
+	}
+	var operators []*configv1.ClusterOperator
+	for _, obj := range m.health.clusterOperators.GetStore().List() {
+		if co, ok := obj.(*configv1.ClusterOperator); ok {
+			operators = append(operators, co.DeepCopy())
+		}
+	}
+	sort.Slice(operators, func(i, j int) bool { return operators[i].Name < operators[j].Name })
+	return operators
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func Test_clusterOperatorProblems(t *testing.T) {
+	objs := []interface{}{
+		&configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns"},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionFalse},
+			}},
+		},
+		&configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress"},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse, Reason: "NoRouters", Message: "no routers"},
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, Message: "router pods crashing"},
+			}},
+		},
+		&configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: "new"}},
+	}
+	want := []string{
+		"clusteroperator/ingress is Degraded: router pods crashing",
+		"clusteroperator/ingress is not Available: NoRouters: no routers",
+		"clusteroperator/new is not Available",
+	}
+	if got := clusterOperatorProblems(objs); !reflect.DeepEqual(got, want) {
+		t.Errorf("clusterOperatorProblems() = %v, want %v", got, want)
+	}
+}
+
+func Test_nodeProblems(t *testing.T) {
+	objs := []interface{}{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}}}},
+	}
+	if got, want := nodeProblems(objs), []string{"node/b is not Ready"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeProblems() = %v, want %v", got, want)
+	}
+}
+
+func Test_machineConfigPoolProblems(t *testing.T) {
+	pool := func(name, updating string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Updated", "status": "True"},
+					map[string]interface{}{"type": "Updating", "status": updating},
+				},
+			},
+		}}
+	}
+	objs := []interface{}{pool("master", "False"), pool("worker", "True")}
+	if got, want := machineConfigPoolProblems(objs), []string{"machineconfigpool/worker is updating"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("machineConfigPoolProblems() = %v, want %v", got, want)
+	}
+}
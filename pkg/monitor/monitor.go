@@ -17,6 +17,9 @@ type Monitor struct {
 	lock    sync.Mutex
 	events  []*Event
 	samples []*sample
+
+	// health is set when the monitor is watching a cluster
+	health *healthSources
 }
 
 // NewMonitor creates a monitor with the default sampling interval.
@@ -11,7 +11,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
-func startNodeMonitoring(ctx context.Context, m Recorder, client kubernetes.Interface) {
+func startNodeMonitoring(ctx context.Context, m Recorder, client kubernetes.Interface) cache.SharedIndexInformer {
 	nodeChangeFns := []func(node, oldNode *corev1.Node) []Condition{
 		func(node, oldNode *corev1.Node) []Condition {
 			var conditions []Condition
@@ -94,4 +94,5 @@ func startNodeMonitoring(ctx context.Context, m Recorder, client kubernetes.Inte
 	})
 
 	go nodeInformer.Run(ctx.Done())
+	return nodeInformer
 }
@@ -15,7 +15,7 @@ import (
 	configclientset "github.com/openshift/client-go/config/clientset/versioned"
 )
 
-func startClusterOperatorMonitoring(ctx context.Context, m Recorder, client configclientset.Interface) {
+func startClusterOperatorMonitoring(ctx context.Context, m Recorder, client configclientset.Interface) cache.SharedIndexInformer {
 	coInformer := cache.NewSharedIndexInformer(
 		NewErrorRecordingListWatcher(m, &cache.ListWatch{
 			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
@@ -277,6 +277,7 @@ func startClusterOperatorMonitoring(ctx context.Context, m Recorder, client conf
 	})
 
 	go cvInformer.Run(ctx.Done())
+	return coInformer
 }
 
 func locateClusterOperator(co *configv1.ClusterOperator) string {
@@ -0,0 +1,168 @@
+package ginkgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checkpointFile is the name of the file in the JUnit directory that records the
+// result of every finished test.
+const checkpointFile = "checkpoint.jsonl"
+
+// checkpointRecord is a single line of the checkpoint file.
+type checkpointRecord struct {
+	Name     string        `json:"name"`
+	Result   TestResult    `json:"result"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output,omitempty"`
+}
+
+// checkpoint appends the result of each finished test to a file as it completes, so
+// that a run that is interrupted can report or resume from the tests that finished.
+type checkpoint struct {
+	lock  sync.Mutex
+	f     *os.File
+	tests []*testCase
+}
+
+// openCheckpoint opens the checkpoint file in dir. Unless resume is set any previous
+// content is discarded.
+func openCheckpoint(dir string, resume bool) (*checkpoint, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(filepath.Join(dir, checkpointFile), flags, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint{f: f}, nil
+}
+
+// Record appends the result of a finished test to the checkpoint. Tests that did
+// not finish are ignored.
+func (c *checkpoint) Record(test *testCase) error {
+	record := &checkpointRecord{
+		Name:     test.name,
+		Start:    test.start,
+		End:      test.end,
+		Duration: test.duration,
+		Output:   string(test.out),
+	}
+	switch {
+	case test.success:
+		record.Result = TestResultPass
+	case test.skipped:
+		record.Result = TestResultSkip
+	case test.failed:
+		record.Result = TestResultFail
+	default:
+		return nil
+	}
+	out, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.tests = append(c.tests, test)
+	if _, err := c.f.Write(append(out, '\n')); err != nil {
+		return err
+	}
+	return c.f.Sync()
+}
+
+// Wrap records each test to the checkpoint after fn completes.
+func (c *checkpoint) Wrap(fn TestFunc, errFn func(error)) TestFunc {
+	return func(ctx context.Context, test *testCase) {
+		fn(ctx, test)
+		if err := c.Record(test); err != nil {
+			errFn(err)
+		}
+	}
+}
+
+// Tests returns the tests recorded so far by this run.
+func (c *checkpoint) Tests() []*testCase {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	copied := make([]*testCase, len(c.tests))
+	copy(copied, c.tests)
+	return copied
+}
+
+func (c *checkpoint) Close() error {
+	return c.f.Close()
+}
+
+// readCheckpoint returns the records of a previous run in dir, grouped by test name
+// in the order they were recorded. A missing checkpoint returns no records.
+func readCheckpoint(dir string, errOut io.Writer) (map[string][]*checkpointRecord, error) {
+	f, err := os.Open(filepath.Join(dir, checkpointFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	records := make(map[string][]*checkpointRecord)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		record := &checkpointRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			// the last line may be truncated if the process was killed while writing
+			fmt.Fprintf(errOut, "warning: ignoring invalid checkpoint record on line %d: %v\n", line, err)
+			continue
+		}
+		records[record.Name] = append(records[record.Name], record)
+	}
+	return records, scanner.Err()
+}
+
+// resumeTests splits tests into those that must run and those that already passed
+// or were skipped in a previous run, copying the previous result onto the latter.
+// Each record is applied to at most one test so that repeated tests are only
+// skipped as many times as they were recorded.
+func resumeTests(tests []*testCase, records map[string][]*checkpointRecord) (run, resumed []*testCase) {
+	for _, test := range tests {
+		var record *checkpointRecord
+		for i, r := range records[test.name] {
+			if r.Result == TestResultPass || r.Result == TestResultSkip {
+				record = r
+				records[test.name] = append(records[test.name][:i:i], records[test.name][i+1:]...)
+				break
+			}
+		}
+		if record == nil {
+			run = append(run, test)
+			continue
+		}
+		test.start = record.Start
+		test.end = record.End
+		test.duration = record.Duration
+		test.out = []byte(record.Output)
+		switch record.Result {
+		case TestResultPass:
+			test.success = true
+		case TestResultSkip:
+			test.skipped = true
+		}
+		resumed = append(resumed, test)
+	}
+	return run, resumed
+}
@@ -0,0 +1,54 @@
+package ginkgo
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cp, err := openCheckpoint(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []*testCase{
+		{name: "passed", success: true, duration: time.Second, out: []byte("ok")},
+		{name: "skipped", skipped: true},
+		{name: "failed", failed: true},
+		{name: "unfinished"},
+	} {
+		if err := cp.Record(test); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := readCheckpoint(dir, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []*testCase{{name: "passed"}, {name: "passed"}, {name: "skipped"}, {name: "failed"}, {name: "unfinished"}, {name: "new"}}
+	run, resumed := resumeTests(tests, records)
+	if got, want := testNames(run), []string{"passed", "failed", "unfinished", "new"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("run = %v, want %v", got, want)
+	}
+	if got, want := testNames(resumed), []string{"passed", "skipped"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("resumed = %v, want %v", got, want)
+	}
+	if !resumed[0].success || resumed[0].duration != time.Second || string(resumed[0].out) != "ok" {
+		t.Errorf("unexpected resumed result: %#v", resumed[0])
+	}
+	if !resumed[1].skipped {
+		t.Errorf("unexpected resumed result: %#v", resumed[1])
+	}
+}
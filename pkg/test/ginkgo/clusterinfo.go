@@ -0,0 +1,69 @@
+package ginkgo
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclientset "github.com/openshift/client-go/config/clientset/versioned"
+)
+
+// clusterInfo describes the cluster a suite ran against.
+type clusterInfo struct {
+	Version     string
+	Platform    string
+	NetworkType string
+}
+
+// discoverClusterInfo reads the cluster version, platform and network type from the
+// cluster referenced by the kubeconfig file, or by the default kube configuration if
+// it is empty. Fields that cannot be read are left empty.
+func discoverClusterInfo(kubeconfig string) (*clusterInfo, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	clusterConfig, err := cfg.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load client configuration: %v", err)
+	}
+	client, err := configclientset.NewForConfig(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &clusterInfo{}
+	var errs []error
+	if cv, err := client.ConfigV1().ClusterVersions().Get("version", metav1.GetOptions{}); err == nil {
+		info.Version = clusterVersionString(cv)
+	} else {
+		errs = append(errs, err)
+	}
+	if infra, err := client.ConfigV1().Infrastructures().Get("cluster", metav1.GetOptions{}); err == nil {
+		info.Platform = string(infra.Status.Platform)
+		if infra.Status.PlatformStatus != nil && len(infra.Status.PlatformStatus.Type) > 0 {
+			info.Platform = string(infra.Status.PlatformStatus.Type)
+		}
+	} else {
+		errs = append(errs, err)
+	}
+	if network, err := client.ConfigV1().Networks().Get("cluster", metav1.GetOptions{}); err == nil {
+		info.NetworkType = network.Status.NetworkType
+	} else {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return info, fmt.Errorf("could not read all cluster information: %v", errs)
+	}
+	return info, nil
+}
+
+func clusterVersionString(cv *configv1.ClusterVersion) string {
+	for _, history := range cv.Status.History {
+		if history.State == configv1.CompletedUpdate {
+			return history.Version
+		}
+	}
+	return cv.Status.Desired.Version
+}
@@ -0,0 +1,298 @@
+package ginkgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// clusterRunTestName is the synthetic test case reported for a cluster the suite
+// could not be run against.
+const clusterRunTestName = "Run the suite against the cluster"
+
+// interruptHandlers are called when the run is interrupted twice, before the
+// process exits.
+type interruptHandlers struct {
+	lock sync.Mutex
+	fns  []func()
+}
+
+func (h *interruptHandlers) Add(fn func()) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.fns = append(h.fns, fn)
+}
+
+func (h *interruptHandlers) Run() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, fn := range h.fns {
+		fn()
+	}
+}
+
+// clusterRun is the run of the suite against one of several clusters.
+type clusterRun struct {
+	name       string
+	kubeconfig string
+
+	result *suiteResult
+	err    error
+}
+
+// runClusters runs the suite against every cluster in opt.Kubeconfigs at the same
+// time. Each cluster has its own worker pool, monitor and report in a directory of
+// opt.JUnitDir named after the cluster. The aggregated report holds a child suite
+// per cluster, and a matrix of the tests that failed on any cluster is printed.
+func (opt *Options) runClusters(ctx context.Context, interrupted *interruptHandlers, suite *TestSuite, tests []*testCase, durations map[string]time.Duration, parallelism int, timeout time.Duration) error {
+	var clusters []*clusterRun
+	names := make(map[string]int)
+	for _, kubeconfig := range opt.Kubeconfigs {
+		info, err := discoverClusterInfo(kubeconfig)
+		if err != nil {
+			fmt.Fprintf(opt.ErrOut, "warning: Unable to describe the cluster of %s: %v\n", kubeconfig, err)
+		}
+		clusters = append(clusters, &clusterRun{
+			name:       clusterName(kubeconfig, info, names),
+			kubeconfig: kubeconfig,
+		})
+	}
+
+	// lines of output are prefixed with the cluster they belong to
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+	for _, cluster := range clusters {
+		clusterOpt := *opt
+		clusterOpt.Kubeconfigs = nil
+		clusterOpt.kubeconfig = cluster.kubeconfig
+		out := newPrefixWriter(opt.Out, fmt.Sprintf("[%s] ", cluster.name), &lock)
+		errOut := newPrefixWriter(opt.ErrOut, fmt.Sprintf("[%s] ", cluster.name), &lock)
+		clusterOpt.Out, clusterOpt.ErrOut = out, errOut
+		if len(opt.JUnitDir) > 0 {
+			clusterOpt.JUnitDir = filepath.Join(opt.JUnitDir, cluster.name)
+			if err := os.MkdirAll(clusterOpt.JUnitDir, 0755); err != nil {
+				return fmt.Errorf("could not create --junit-dir: %v", err)
+			}
+		}
+		clusterTests := make([]*testCase, 0, len(tests))
+		for _, test := range tests {
+			clusterTests = append(clusterTests, test.clone())
+		}
+
+		wg.Add(1)
+		go func(cluster *clusterRun, clusterOpt *Options) {
+			defer wg.Done()
+			defer out.Flush()
+			defer errOut.Flush()
+			cluster.result, cluster.err = clusterOpt.runSuite(ctx, interrupted, suite, clusterTests, durations, parallelism, timeout)
+		}(cluster, &clusterOpt)
+	}
+	wg.Wait()
+
+	duration := time.Now().Sub(start).Round(time.Second)
+
+	matrix := clusterMatrix(clusters)
+	fmt.Fprintf(opt.Out, "\nCluster matrix:\n\n%s\n", matrix)
+
+	if len(opt.JUnitDir) > 0 {
+		report := newClusterJUnitSuite("openshift-tests-private", clusters, duration, opt.junitProperties(suite.Name, parallelism, timeout, nil), opt.JUnitBySubteam)
+		if err := writeJUnitReport("junit_e2e", report, opt.JUnitDir, opt.ErrOut); err != nil {
+			fmt.Fprintf(opt.Out, "error: Unable to write e2e JUnit results: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(opt.JUnitDir, "cluster-matrix.txt"), []byte(matrix), 0640); err != nil {
+			fmt.Fprintf(opt.Out, "error: Unable to write cluster matrix: %v", err)
+		}
+	}
+
+	var failed []string
+	for _, cluster := range clusters {
+		if cluster.err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", cluster.name, cluster.err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("the suite failed on %d of %d clusters:\n%s", len(failed), len(clusters), strings.Join(failed, "\n"))
+	}
+	return ctx.Err()
+}
+
+// clusterName names a cluster after its platform, or after its kubeconfig file if
+// the platform is not known. Names already in use get a numeric suffix.
+func clusterName(kubeconfig string, info *clusterInfo, names map[string]int) string {
+	var name string
+	if info != nil && len(info.Platform) > 0 {
+		name = strings.ToLower(info.Platform)
+	} else {
+		name = strings.TrimSuffix(filepath.Base(kubeconfig), filepath.Ext(kubeconfig))
+	}
+	name = unsafeFilenameCharacters.ReplaceAllString(name, "_")
+	names[name]++
+	if n := names[name]; n > 1 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+	return name
+}
+
+// newClusterJUnitSuite builds the aggregated report of a run against several
+// clusters with a child suite per cluster. Diagnostic bundles are linked relative
+// to the directory of the aggregated report.
+func newClusterJUnitSuite(name string, clusters []*clusterRun, duration time.Duration, properties []*TestSuiteProperty, bySubteam bool) *JUnitTestSuite {
+	s := &JUnitTestSuite{
+		Name:       name,
+		Duration:   duration.Seconds(),
+		Properties: properties,
+	}
+	for _, cluster := range clusters {
+		var child *JUnitTestSuite
+		if cluster.result == nil {
+			child = newJUnitSuite(cluster.name, nil, 0, nil, bySubteam, &JUnitTestCase{
+				Name:          clusterRunTestName,
+				FailureOutput: &FailureOutput{Output: fmt.Sprintf("The suite could not be run against %s: %v", cluster.kubeconfig, cluster.err)},
+			})
+		} else {
+			for _, test := range cluster.result.tests {
+				if len(test.diagnostics) > 0 {
+					test.diagnostics = filepath.Join(cluster.name, test.diagnostics)
+				}
+			}
+			child = newJUnitSuite(cluster.name, cluster.result.tests, cluster.result.duration, cluster.result.properties, bySubteam, cluster.result.synthetic...)
+		}
+		s.NumTests += child.NumTests
+		s.NumSkipped += child.NumSkipped
+		s.NumFailed += child.NumFailed
+		s.Children = append(s.Children, child)
+	}
+	return s
+}
+
+// clusterMatrix returns a table of the tests that failed or flaked on any cluster
+// and their result on each cluster.
+func clusterMatrix(clusters []*clusterRun) string {
+	results := make([]map[string]string, len(clusters))
+	failing := make(map[string]struct{})
+	for i, cluster := range clusters {
+		results[i] = make(map[string]string)
+		if cluster.result == nil {
+			continue
+		}
+		for _, test := range cluster.result.tests {
+			result := "pass"
+			switch {
+			case test.failed:
+				result = "FAIL"
+				if _, ok := cluster.result.flakes[test.name]; ok {
+					result = "flake"
+				}
+			case test.skipped:
+				result = "skip"
+			}
+			if result == "FAIL" || result == "flake" {
+				failing[test.name] = struct{}{}
+			}
+			// a test run several times reports its worst result
+			if matrixRank[result] > matrixRank[results[i][test.name]] {
+				results[i][test.name] = result
+			}
+		}
+	}
+
+	names := make([]string, 0, len(failing))
+	for name := range failing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	unreachable := false
+	for _, cluster := range clusters {
+		unreachable = unreachable || cluster.result == nil
+	}
+	if len(names) == 0 && !unreachable {
+		return "No test failed on any cluster\n"
+	}
+
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	header := []string{"TEST"}
+	for _, cluster := range clusters {
+		header = append(header, strings.ToUpper(cluster.name))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, cluster := range clusters {
+		if cluster.result == nil {
+			row := []string{clusterRunTestName}
+			for _, other := range clusters {
+				if other == cluster {
+					row = append(row, "FAIL")
+				} else {
+					row = append(row, "-")
+				}
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+	}
+	for _, name := range names {
+		row := []string{name}
+		for i := range clusters {
+			result, ok := results[i][name]
+			if !ok {
+				result = "-"
+			}
+			row = append(row, result)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// matrixRank orders the results shown in the cluster matrix from best to worst.
+var matrixRank = map[string]int{"": 0, "skip": 1, "pass": 2, "flake": 3, "FAIL": 4}
+
+// prefixWriter writes each complete line to the underlying writer with a prefix,
+// holding a lock shared with the other writers to the same output.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	lock   *sync.Mutex
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, prefix string, lock *sync.Mutex) *prefixWriter {
+	return &prefixWriter{w: w, prefix: prefix, lock: lock}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(w.w, "%s%s", w.prefix, w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any incomplete last line.
+func (w *prefixWriter) Flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.buf) > 0 {
+		fmt.Fprintf(w.w, "%s%s\n", w.prefix, w.buf)
+		w.buf = nil
+	}
+}
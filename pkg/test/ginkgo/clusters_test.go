@@ -0,0 +1,95 @@
+package ginkgo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_clusterName(t *testing.T) {
+	names := make(map[string]int)
+	tests := []struct {
+		kubeconfig string
+		info       *clusterInfo
+		want       string
+	}{
+		{kubeconfig: "/tmp/a.kubeconfig", info: &clusterInfo{Platform: "AWS"}, want: "aws"},
+		{kubeconfig: "/tmp/b.kubeconfig", info: &clusterInfo{Platform: "AWS"}, want: "aws-2"},
+		{kubeconfig: "/tmp/vsphere-ipi.kubeconfig", want: "vsphere-ipi"},
+	}
+	for _, tt := range tests {
+		if got := clusterName(tt.kubeconfig, tt.info, names); got != tt.want {
+			t.Errorf("clusterName(%q) = %q, want %q", tt.kubeconfig, got, tt.want)
+		}
+	}
+}
+
+func Test_clusterMatrix(t *testing.T) {
+	result := func(tests ...*testCase) *suiteResult {
+		return &suiteResult{tests: tests, flakes: map[string]struct{}{"flaky": {}}}
+	}
+	clusters := []*clusterRun{
+		{name: "aws", result: result(
+			&testCase{name: "a", success: true},
+			&testCase{name: "b", failed: true},
+			&testCase{name: "flaky", failed: true},
+		)},
+		{name: "gcp", result: result(
+			&testCase{name: "a", failed: true},
+			&testCase{name: "a", success: true},
+			&testCase{name: "b", skipped: true},
+		)},
+		{name: "vsphere", err: fmt.Errorf("could not load client configuration")},
+	}
+	want := strings.Join([]string{
+		"TEST                               AWS    GCP   VSPHERE",
+		"Run the suite against the cluster  -      -     FAIL",
+		"a                                  pass   FAIL  -",
+		"b                                  FAIL   skip  -",
+		"flaky                              flake  -     -",
+		"",
+	}, "\n")
+	if got := clusterMatrix(clusters); got != want {
+		t.Errorf("unexpected matrix:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got, want := clusterMatrix(nil), "No test failed on any cluster\n"; got != want {
+		t.Errorf("clusterMatrix() = %q, want %q", got, want)
+	}
+}
+
+func Test_newClusterJUnitSuite(t *testing.T) {
+	clusters := []*clusterRun{
+		{name: "aws", result: &suiteResult{tests: []*testCase{
+			{name: "a", success: true},
+			{name: "b", failed: true, diagnostics: "artifacts/123"},
+		}}},
+		{name: "gcp", err: fmt.Errorf("unreachable")},
+	}
+	s := newClusterJUnitSuite("openshift-tests-private", clusters, time.Minute, nil, false)
+	if s.NumTests != 3 || s.NumFailed != 2 || len(s.Children) != 2 {
+		t.Fatalf("unexpected counts: tests=%d failed=%d children=%d", s.NumTests, s.NumFailed, len(s.Children))
+	}
+	failure := s.Children[0].TestCases[1].FailureOutput
+	if failure == nil || !strings.Contains(failure.Output, "Diagnostics: aws/artifacts/123") {
+		t.Errorf("unexpected failure: %#v", failure)
+	}
+	if s.Children[1].TestCases[0].Name != clusterRunTestName {
+		t.Errorf("unexpected test cases: %#v", s.Children[1].TestCases)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var lock sync.Mutex
+	w := newPrefixWriter(buf, "[aws] ", &lock)
+	fmt.Fprint(w, "started: a\n\npar")
+	fmt.Fprint(w, "tial")
+	w.Flush()
+	if got, want := buf.String(), "[aws] started: a\n[aws] \n[aws] partial\n"; got != want {
+		t.Errorf("unexpected output %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,286 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CompareOptions reports what changed between the JUnit reports of two runs.
+type CompareOptions struct {
+	// DurationThreshold is the relative increase in the duration of a passing test,
+	// for example 0.5 for 50%, above which it is reported as slower.
+	DurationThreshold float64
+	// MinimumDuration ignores duration increases smaller than this.
+	MinimumDuration time.Duration
+	Output          string
+
+	Out, ErrOut io.Writer
+}
+
+// comparison holds the changes between two runs grouped by subteam.
+type comparison struct {
+	Subteams []*subteamComparison `json:"subteams"`
+}
+
+// subteamComparison holds the changes to the tests of one subteam.
+type subteamComparison struct {
+	Sig     string `json:"sig"`
+	Subteam string `json:"subteam"`
+
+	NewlyFailing []*testChange `json:"newlyFailing,omitempty"`
+	NewlyPassing []*testChange `json:"newlyPassing,omitempty"`
+	NewlySkipped []*testChange `json:"newlySkipped,omitempty"`
+	Missing      []*testChange `json:"missing,omitempty"`
+	Slower       []*testChange `json:"slower,omitempty"`
+}
+
+// testChange is the result of a test in the old and the new run. Durations are in
+// seconds.
+type testChange struct {
+	Name        string     `json:"name"`
+	Old         TestResult `json:"old"`
+	New         TestResult `json:"new,omitempty"`
+	OldDuration float64    `json:"oldDuration"`
+	NewDuration float64    `json:"newDuration,omitempty"`
+	Failure     string     `json:"failure,omitempty"`
+}
+
+// compareCategories are the kinds of change in the order they are reported.
+var compareCategories = []struct {
+	title   string
+	changes func(*subteamComparison) []*testChange
+}{
+	{"Newly failing", func(s *subteamComparison) []*testChange { return s.NewlyFailing }},
+	{"Newly passing", func(s *subteamComparison) []*testChange { return s.NewlyPassing }},
+	{"Newly skipped", func(s *subteamComparison) []*testChange { return s.NewlySkipped }},
+	{"Missing", func(s *subteamComparison) []*testChange { return s.Missing }},
+	{"Slower", func(s *subteamComparison) []*testChange { return s.Slower }},
+}
+
+func (opt *CompareOptions) Run(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("specify the JUnit reports of the old and the new run")
+	}
+	if opt.DurationThreshold < 0 {
+		return fmt.Errorf("--duration-threshold may not be negative")
+	}
+	switch opt.Output {
+	case "", "text", "markdown", "json":
+	default:
+		return fmt.Errorf("--output must be one of text, markdown or json")
+	}
+	var runs [2][]*reportResult
+	for i, path := range args {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		suites, err := readJUnitSuites(data)
+		if err != nil {
+			return fmt.Errorf("could not read JUnit report %s: %v", path, err)
+		}
+		runs[i], _, _ = collectReportResults(suites)
+	}
+	c := compareResults(runs[0], runs[1], opt.DurationThreshold, opt.MinimumDuration)
+
+	switch opt.Output {
+	case "", "text":
+		return writeComparisonText(opt.Out, c)
+	case "markdown":
+		writeComparisonMarkdown(opt.Out, c)
+		return nil
+	default:
+		out, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(opt.Out, string(out))
+		return nil
+	}
+}
+
+// compareResults returns the tests that started failing, passing or being skipped,
+// that were not run, or whose duration grew by more than threshold and minimum in
+// the new run.
+func compareResults(oldResults, newResults []*reportResult, threshold float64, minimum time.Duration) *comparison {
+	byName := make(map[string]*reportResult)
+	for _, result := range newResults {
+		byName[result.name] = result
+	}
+	subteams := make(map[string]*subteamComparison)
+	c := &comparison{}
+	group := func(result *reportResult) *subteamComparison {
+		sig, subteam := result.sig, result.subteam
+		if len(sig) == 0 {
+			sig = "ungrouped"
+		}
+		key := sig + " " + subteam
+		s, ok := subteams[key]
+		if !ok {
+			s = &subteamComparison{Sig: sig, Subteam: subteam}
+			subteams[key] = s
+			c.Subteams = append(c.Subteams, s)
+		}
+		return s
+	}
+
+	for _, before := range oldResults {
+		after, ok := byName[before.name]
+		change := &testChange{
+			Name:        before.name,
+			Old:         compareResult(before),
+			OldDuration: before.duration,
+		}
+		if !ok {
+			s := group(before)
+			s.Missing = append(s.Missing, change)
+			continue
+		}
+		change.New = compareResult(after)
+		change.NewDuration = after.duration
+		s := group(after)
+		switch {
+		case after.status == rpFailed && before.status != rpFailed:
+			change.Failure = lastLinesUntil(after.failure, 10)
+			s.NewlyFailing = append(s.NewlyFailing, change)
+		case after.status == rpPassed && before.status == rpFailed:
+			s.NewlyPassing = append(s.NewlyPassing, change)
+		case after.status == rpSkipped && before.status != rpSkipped:
+			s.NewlySkipped = append(s.NewlySkipped, change)
+		case after.status == rpPassed && before.status == rpPassed && before.duration > 0:
+			increase := time.Duration((after.duration - before.duration) * float64(time.Second))
+			if after.duration > before.duration*(1+threshold) && increase >= minimum {
+				s.Slower = append(s.Slower, change)
+			}
+		}
+	}
+
+	// only report subteams with changes
+	changed := c.Subteams[:0]
+	for _, s := range c.Subteams {
+		for _, category := range compareCategories {
+			if len(category.changes(s)) > 0 {
+				changed = append(changed, s)
+				break
+			}
+		}
+	}
+	c.Subteams = changed
+	sort.Slice(c.Subteams, func(i, j int) bool {
+		if c.Subteams[i].Sig != c.Subteams[j].Sig {
+			return c.Subteams[i].Sig < c.Subteams[j].Sig
+		}
+		return c.Subteams[i].Subteam < c.Subteams[j].Subteam
+	})
+	for _, s := range c.Subteams {
+		for _, changes := range [][]*testChange{s.NewlyFailing, s.NewlyPassing, s.NewlySkipped, s.Missing, s.Slower} {
+			sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+		}
+	}
+	return c
+}
+
+func compareResult(result *reportResult) TestResult {
+	switch {
+	case result.flake:
+		return TestResultFlake
+	case result.status == rpFailed:
+		return TestResultFail
+	case result.status == rpSkipped:
+		return TestResultSkip
+	}
+	return TestResultPass
+}
+
+// summary returns the number of changes of each kind.
+func (c *comparison) summary() string {
+	var parts []string
+	for _, category := range compareCategories {
+		count := 0
+		for _, s := range c.Subteams {
+			count += len(category.changes(s))
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", count, strings.ToLower(category.title)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s *subteamComparison) title() string {
+	return strings.TrimSpace(fmt.Sprintf("[%s] %s", s.Sig, s.Subteam))
+}
+
+func (change *testChange) durations() string {
+	if len(change.New) == 0 {
+		return secondsDuration(change.OldDuration).String()
+	}
+	return fmt.Sprintf("%s -> %s", secondsDuration(change.OldDuration), secondsDuration(change.NewDuration))
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second / 10)
+}
+
+func writeComparisonText(out io.Writer, c *comparison) error {
+	if len(c.Subteams) == 0 {
+		fmt.Fprintln(out, "No changes")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, s := range c.Subteams {
+		fmt.Fprintf(w, "%s\n", s.title())
+		for _, category := range compareCategories {
+			changes := category.changes(s)
+			if len(changes) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "  %s:\n", category.title)
+			for _, change := range changes {
+				result := string(change.Old)
+				if len(change.New) > 0 {
+					result += " -> " + string(change.New)
+				}
+				fmt.Fprintf(w, "    %s\t%s\t%s\n", result, change.durations(), change.Name)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, c.summary())
+	return w.Flush()
+}
+
+func writeComparisonMarkdown(out io.Writer, c *comparison) {
+	if len(c.Subteams) == 0 {
+		fmt.Fprintln(out, "No changes")
+		return
+	}
+	for _, s := range c.Subteams {
+		fmt.Fprintf(out, "## %s\n\n", s.title())
+		for _, category := range compareCategories {
+			changes := category.changes(s)
+			if len(changes) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, "### %s\n\n| Test | Before | After | Duration |\n| --- | --- | --- | --- |\n", category.title)
+			for _, change := range changes {
+				after := string(change.New)
+				if len(after) == 0 {
+					after = "-"
+				}
+				fmt.Fprintf(out, "| %s | %s | %s | %s |\n", markdownEscape(change.Name), change.Old, after, change.durations())
+			}
+			fmt.Fprintln(out)
+		}
+	}
+	fmt.Fprintf(out, "**Summary:** %s\n", c.summary())
+}
+
+// markdownEscape keeps a test name from breaking a markdown table.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "[", `\[`, "]", `\]`).Replace(s)
+}
@@ -0,0 +1,99 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareOptions_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const (
+		probe   = "[sig-node] NODE Author:minmli-High-41579-Liveness probe"
+		evict   = "[sig-node] NODE Author:pmali-High-11600-kubelet evicts pods"
+		reboot  = "[sig-node] NODE Author:pmali-High-11601-reboot [Disruptive]"
+		egress  = "[sig-networking] SDN Author:zzhao-High-25321-egress"
+		scale   = "[sig-networking] SDN Author:zzhao-High-25322-scale"
+		dpdk    = "[sig-networking] SDN Author:zzhao-High-25323-dpdk"
+		fast    = "[sig-networking] SDN Author:zzhao-High-25324-fast"
+		removed = "[sig-networking] SDN Author:zzhao-High-25325-removed"
+	)
+	pass := func(name string, seconds float64) *JUnitTestCase {
+		return &JUnitTestCase{Name: name, Duration: seconds}
+	}
+	fail := func(name string) *JUnitTestCase {
+		return &JUnitTestCase{Name: name, FailureOutput: &FailureOutput{Output: "fail [timed out]"}}
+	}
+	skip := func(name string) *JUnitTestCase {
+		return &JUnitTestCase{Name: name, SkipMessage: &SkipMessage{Message: "skip [no sriov]"}}
+	}
+	write := func(name string, cases ...*JUnitTestCase) string {
+		suite := &JUnitTestSuite{Name: "openshift-tests-private", Children: []*JUnitTestSuite{{Name: "child", TestCases: cases}}}
+		data, err := xml.Marshal(suite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0640); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	oldPath := write("old.xml", pass(probe, 10), fail(evict), pass(reboot, 60), pass(egress, 100), pass(scale, 10), pass(dpdk, 5), pass(fast, 1), pass(removed, 1))
+	newPath := write("new.xml", fail(probe), fail(evict), pass(evict, 20), skip(reboot), pass(egress, 200), pass(scale, 30), pass(dpdk, 5), pass(fast, 1.8))
+
+	buf := &bytes.Buffer{}
+	opt := &CompareOptions{DurationThreshold: 0.5, MinimumDuration: 30 * time.Second, Output: "json", Out: buf}
+	if err := opt.Run([]string{oldPath, newPath}); err != nil {
+		t.Fatal(err)
+	}
+	var c comparison
+	if err := json.Unmarshal(buf.Bytes(), &c); err != nil {
+		t.Fatalf("unexpected output %s: %v", buf.String(), err)
+	}
+	want := comparison{Subteams: []*subteamComparison{
+		{
+			Sig: "sig-networking", Subteam: "SDN",
+			Missing: []*testChange{{Name: removed, Old: TestResultPass, OldDuration: 1}},
+			Slower:  []*testChange{{Name: egress, Old: TestResultPass, New: TestResultPass, OldDuration: 100, NewDuration: 200}},
+		},
+		{
+			Sig: "sig-node", Subteam: "NODE",
+			NewlyFailing: []*testChange{{Name: probe, Old: TestResultPass, New: TestResultFail, OldDuration: 10, Failure: "fail [timed out]"}},
+			NewlyPassing: []*testChange{{Name: evict, Old: TestResultFail, New: TestResultFlake, NewDuration: 20}},
+			NewlySkipped: []*testChange{{Name: reboot, Old: TestResultPass, New: TestResultSkip, OldDuration: 60}},
+		},
+	}}
+	if !reflect.DeepEqual(c, want) {
+		got, _ := json.MarshalIndent(c, "", "  ")
+		t.Fatalf("unexpected comparison:\n%s", got)
+	}
+
+	for output, expected := range map[string][]string{
+		"text":     {"[sig-node] NODE\n  Newly failing:\n    pass -> fail", "1 newly failing, 1 newly passing, 1 newly skipped, 1 missing, 1 slower"},
+		"markdown": {"## [sig-node] NODE", "| \\[sig-networking\\] SDN Author:zzhao-High-25321-egress | pass | pass | 1m40s -> 3m20s |"},
+	} {
+		buf.Reset()
+		opt.Output = output
+		if err := opt.Run([]string{oldPath, newPath}); err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range expected {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("%s output does not contain %q:\n%s", output, s, buf.String())
+			}
+		}
+	}
+}
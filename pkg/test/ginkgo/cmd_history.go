@@ -0,0 +1,91 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"text/tabwriter"
+	"time"
+)
+
+// HistoryOptions reports statistics from the results recorded by run --history-db.
+type HistoryOptions struct {
+	DBPath  string
+	GroupBy string
+	Regex   string
+	Output  string
+
+	Out, ErrOut io.Writer
+}
+
+func (opt *HistoryOptions) Run(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("history does not accept arguments, use --run to select tests")
+	}
+	if len(opt.DBPath) == 0 {
+		return fmt.Errorf("--history-db must be specified")
+	}
+	var key func(*historyResult) string
+	switch opt.GroupBy {
+	case "", "test":
+		key = func(r *historyResult) string { return r.Name }
+	case "subteam":
+		key = (*historyResult).group
+	default:
+		return fmt.Errorf("--by must be one of test or subteam")
+	}
+	var re *regexp.Regexp
+	if len(opt.Regex) > 0 {
+		var err error
+		if re, err = regexp.Compile(opt.Regex); err != nil {
+			return fmt.Errorf("regular expression for filtering tests is invalid: %v", err)
+		}
+	}
+
+	db, err := openHistoryDBReadOnly(opt.DBPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	results, err := db.Results()
+	if err != nil {
+		return err
+	}
+	if re != nil {
+		filtered := results[:0]
+		for _, r := range results {
+			if re.MatchString(r.Name) {
+				filtered = append(filtered, r)
+			}
+		}
+		results = filtered
+	}
+	stats := summarizeHistory(results, key)
+
+	switch opt.Output {
+	case "", "text":
+		w := tabwriter.NewWriter(opt.Out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "RUNS\tPASS\tFLAKE\tFAIL\tP50\tP90\tP99\tFIRST FAILED\tNAME")
+		for _, s := range stats {
+			firstFailed := "-"
+			if s.FirstFailedAt != nil {
+				firstFailed = s.FirstFailedAt.UTC().Format("2006-01-02T15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%.1f%%\t%.1f%%\t%.1f%%\t%s\t%s\t%s\t%s\t%s\n",
+				s.Runs, s.PassRate*100, s.FlakeRate*100, s.FailRate*100,
+				s.P50.Round(time.Second), s.P90.Round(time.Second), s.P99.Round(time.Second),
+				firstFailed, s.Name)
+		}
+		return w.Flush()
+	case "json":
+		out, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(opt.Out, string(out))
+		return nil
+	default:
+		return fmt.Errorf("--output must be one of text or json")
+	}
+}
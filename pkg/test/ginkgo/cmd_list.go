@@ -0,0 +1,157 @@
+package ginkgo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/onsi/ginkgo/config"
+	"sigs.k8s.io/yaml"
+)
+
+// TestInfo describes a single test in the inventory printed by the list command.
+type TestInfo struct {
+	Name     string       `json:"name"`
+	Suites   []string     `json:"suites"`
+	Location string       `json:"location"`
+	Sig      string       `json:"sig,omitempty"`
+	Subteam  string       `json:"subteam,omitempty"`
+	Labels   []string     `json:"labels,omitempty"`
+	Metadata TestMetadata `json:"metadata"`
+	Owners   *Owners      `json:"owners,omitempty"`
+}
+
+// ListOptions prints the inventory of tests known to this binary.
+type ListOptions struct {
+	Suites []*TestSuite
+	// SuiteFile is a YAML suite definition, or a directory of them, that adds to
+	// or replaces the suites in Suites.
+	SuiteFile string
+	// Owners resolves each test to the approvers and reviewers of its nearest
+	// OWNERS file.
+	Owners *OwnersIndex
+	Output string
+
+	Out, ErrOut io.Writer
+}
+
+func (opt *ListOptions) Run(args []string) error {
+	var suite *TestSuite
+	if len(opt.SuiteFile) > 0 {
+		suites, err := loadSuiteFiles(opt.SuiteFile)
+		if err != nil {
+			return err
+		}
+		opt.Suites = mergeSuites(opt.Suites, suites)
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("only a single suite may be listed")
+	}
+	if len(args) == 1 {
+		for _, s := range opt.Suites {
+			if s.Name == args[0] {
+				suite = s
+				break
+			}
+		}
+		if suite == nil {
+			fmt.Fprintf(opt.ErrOut, SuitesString(opt.Suites, "Select a test suite to list:\n\n"))
+			return fmt.Errorf("suite %q does not exist", args[0])
+		}
+	}
+
+	tests, err := testsForSuite(config.GinkgoConfig)
+	if err != nil {
+		return err
+	}
+	if suite != nil {
+		tests = suite.Filter(tests)
+	}
+	if opt.Owners != nil {
+		if err := setOwners(tests, opt.Owners); err != nil {
+			return err
+		}
+	}
+
+	var infos []*TestInfo
+	for _, test := range sortedTests(tests) {
+		infos = append(infos, newTestInfo(test, opt.Suites))
+	}
+
+	return writeTestInfos(opt.Out, opt.Output, infos)
+}
+
+// writeTestInfos prints the inventory in the json, yaml or csv output format.
+func writeTestInfos(w io.Writer, output string, infos []*TestInfo) error {
+	switch output {
+	case "", "json":
+		out, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(out))
+	case "yaml":
+		out, err := yaml.Marshal(infos)
+		if err != nil {
+			return err
+		}
+		w.Write(out)
+	case "csv":
+		return writeTestInfoCSV(w, infos)
+	default:
+		return fmt.Errorf("--output must be one of json, yaml or csv")
+	}
+	return nil
+}
+
+func newTestInfo(test *testCase, suites []*TestSuite) *TestInfo {
+	info := &TestInfo{
+		Name:     test.name,
+		Suites:   []string{},
+		Location: fmt.Sprintf("%s:%d", lastFilenameSegment(test.location.FileName), test.location.LineNumber),
+		Sig:      test.sig,
+		Subteam:  test.subteam,
+		Labels:   testLabels(test.name),
+		Metadata: test.metadata,
+		Owners:   test.owners,
+	}
+	for _, suite := range suites {
+		if suite.Matches(test.name) {
+			info.Suites = append(info.Suites, suite.Name)
+		}
+	}
+	return info
+}
+
+func writeTestInfoCSV(out io.Writer, infos []*TestInfo) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"name", "suites", "location", "sig", "subteam", "labels", "author", "importance", "caseIDs", "prefixes", "approvers", "reviewers"}); err != nil {
+		return err
+	}
+	for _, info := range infos {
+		owners := info.Owners
+		if owners == nil {
+			owners = &Owners{}
+		}
+		if err := w.Write([]string{
+			info.Name,
+			strings.Join(info.Suites, ";"),
+			info.Location,
+			info.Sig,
+			info.Subteam,
+			strings.Join(info.Labels, ";"),
+			info.Metadata.Author,
+			info.Metadata.Importance,
+			strings.Join(info.Metadata.CaseIDs, ";"),
+			strings.Join(info.Metadata.Prefixes, ";"),
+			strings.Join(owners.Approvers, ";"),
+			strings.Join(owners.Reviewers, ";"),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,152 @@
+package ginkgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteTestInfos(t *testing.T) {
+	full := &TestInfo{
+		Name:     "[sig-node] NODE Author:minmli-High-41579-drain a worker [Serial]",
+		Suites:   []string{"openshift/conformance/serial", "all"},
+		Location: "node.go:42",
+		Sig:      "sig-node",
+		Subteam:  "NODE",
+		Labels:   []string{"Serial"},
+		Metadata: TestMetadata{Author: "minmli", Importance: "High", CaseIDs: []string{"41579", "41580"}, Prefixes: []string{"NonPreRelease"}},
+		Owners:   &Owners{Path: "node/OWNERS", Approvers: []string{"minmli", "pmali"}, Reviewers: []string{"sunilc"}},
+	}
+	empty := &TestInfo{
+		Name:     "[sig-node] should run",
+		Suites:   []string{},
+		Location: "node.go:7",
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		infos  []*TestInfo
+		want   string
+	}{
+		{
+			name:   "json",
+			output: "json",
+			infos:  []*TestInfo{full},
+			want: `[
+  {
+    "name": "[sig-node] NODE Author:minmli-High-41579-drain a worker [Serial]",
+    "suites": [
+      "openshift/conformance/serial",
+      "all"
+    ],
+    "location": "node.go:42",
+    "sig": "sig-node",
+    "subteam": "NODE",
+    "labels": [
+      "Serial"
+    ],
+    "metadata": {
+      "author": "minmli",
+      "importance": "High",
+      "caseIDs": [
+        "41579",
+        "41580"
+      ],
+      "prefixes": [
+        "NonPreRelease"
+      ]
+    },
+    "owners": {
+      "path": "node/OWNERS",
+      "approvers": [
+        "minmli",
+        "pmali"
+      ],
+      "reviewers": [
+        "sunilc"
+      ]
+    }
+  }
+]
+`,
+		},
+		{
+			name:   "json is the default",
+			output: "",
+			infos:  []*TestInfo{empty},
+			want: `[
+  {
+    "name": "[sig-node] should run",
+    "suites": [],
+    "location": "node.go:7",
+    "metadata": {}
+  }
+]
+`,
+		},
+		{
+			name:   "yaml",
+			output: "yaml",
+			infos:  []*TestInfo{full, empty},
+			want: `- labels:
+  - Serial
+  location: node.go:42
+  metadata:
+    author: minmli
+    caseIDs:
+    - "41579"
+    - "41580"
+    importance: High
+    prefixes:
+    - NonPreRelease
+  name: '[sig-node] NODE Author:minmli-High-41579-drain a worker [Serial]'
+  owners:
+    approvers:
+    - minmli
+    - pmali
+    path: node/OWNERS
+    reviewers:
+    - sunilc
+  sig: sig-node
+  subteam: NODE
+  suites:
+  - openshift/conformance/serial
+  - all
+- location: node.go:7
+  metadata: {}
+  name: '[sig-node] should run'
+  suites: []
+`,
+		},
+		{
+			name:   "csv",
+			output: "csv",
+			infos:  []*TestInfo{full, empty},
+			want: `name,suites,location,sig,subteam,labels,author,importance,caseIDs,prefixes,approvers,reviewers
+[sig-node] NODE Author:minmli-High-41579-drain a worker [Serial],openshift/conformance/serial;all,node.go:42,sig-node,NODE,Serial,minmli,High,41579;41580,NonPreRelease,minmli;pmali,sunilc
+[sig-node] should run,,node.go:7,,,,,,,,,
+`,
+		},
+		{
+			name:   "csv without tests",
+			output: "csv",
+			want: `name,suites,location,sig,subteam,labels,author,importance,caseIDs,prefixes,approvers,reviewers
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := writeTestInfos(out, tt.output, tt.infos); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("unexpected %s output:\n%s\nwant:\n%s", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if err := writeTestInfos(&bytes.Buffer{}, "table", nil); err == nil {
+		t.Errorf("expected an error for an unknown output format")
+	}
+}
@@ -0,0 +1,337 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReportUploadOptions uploads the JUnit reports of a run to ReportPortal as a launch.
+type ReportUploadOptions struct {
+	ReportPortalURL string
+	Project         string
+	Token           string
+	Launch          string
+	Description     string
+	// Attributes are key:value pairs added to the launch.
+	Attributes []string
+
+	Out, ErrOut io.Writer
+}
+
+// reportResult is the merged result of all test cases with the same name.
+type reportResult struct {
+	name     string
+	sig      string
+	subteam  string
+	metadata TestMetadata
+	duration float64
+	status   string
+	// flake is set if the test both failed and passed
+	flake   bool
+	failure string
+	output  string
+}
+
+func (opt *ReportUploadOptions) Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("at least one JUnit report must be specified")
+	}
+	if len(opt.ReportPortalURL) == 0 {
+		return fmt.Errorf("--reportportal-url must be specified")
+	}
+	if len(opt.Project) == 0 {
+		return fmt.Errorf("--project must be specified")
+	}
+	if len(opt.Launch) == 0 {
+		return fmt.Errorf("--launch must be specified")
+	}
+	var launchAttributes []rpAttribute
+	for _, attr := range opt.Attributes {
+		parts := strings.SplitN(attr, ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf("--attribute must be of the form key:value, got %q", attr)
+		}
+		launchAttributes = append(launchAttributes, rpAttribute{Key: parts[0], Value: parts[1]})
+	}
+
+	var suites []*JUnitTestSuite
+	for _, path := range args {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		s, err := readJUnitSuites(data)
+		if err != nil {
+			return fmt.Errorf("could not read JUnit report %s: %v", path, err)
+		}
+		suites = append(suites, s...)
+	}
+	results, monitor, duration := collectReportResults(suites)
+	if monitor == nil {
+		// the report only has a monitor test if errors were detected, otherwise the
+		// timeline is read from the file written next to it
+		timeline, err := readMonitorTimeline(args)
+		if err != nil {
+			return err
+		}
+		if len(timeline) > 0 {
+			monitor = &JUnitTestCase{Name: monitorTestName, SystemOut: timeline}
+		}
+	}
+
+	client := newReportPortalClient(opt.ReportPortalURL, opt.Project, opt.Token)
+	// the reports do not record when tests started, so every item is placed at the
+	// start of a launch that ends now
+	start := time.Now().Add(-duration)
+	launch, err := client.StartLaunch(&rpStartRequest{
+		Name:        opt.Launch,
+		Description: opt.Description,
+		StartTime:   rpTime(start),
+		Mode:        "DEFAULT",
+		Attributes:  launchAttributes,
+	})
+	if err != nil {
+		return err
+	}
+	uploadErr := uploadReportResults(client, launch, start, results, monitor)
+	if err := client.FinishLaunch(launch, &rpFinishRequest{EndTime: rpTime(start.Add(duration))}); err != nil {
+		if uploadErr != nil {
+			return uploadErr
+		}
+		return err
+	}
+	if uploadErr != nil {
+		return uploadErr
+	}
+	fmt.Fprintf(opt.Out, "Uploaded %d tests to launch %s (%s)\n", len(results), opt.Launch, launch)
+	return nil
+}
+
+// collectReportResults flattens the suites into one result per test name, and
+// returns the synthetic monitor test separately along with the longest suite
+// duration.
+func collectReportResults(suites []*JUnitTestSuite) ([]*reportResult, *JUnitTestCase, time.Duration) {
+	var results []*reportResult
+	var monitor *JUnitTestCase
+	var seconds float64
+	byName := make(map[string]*reportResult)
+
+	var add func(suite *JUnitTestSuite)
+	add = func(suite *JUnitTestSuite) {
+		if suite.Duration > seconds {
+			seconds = suite.Duration
+		}
+		for _, test := range suite.TestCases {
+			if test.Name == monitorTestName {
+				monitor = test
+				continue
+			}
+			status := rpPassed
+			switch {
+			case test.FailureOutput != nil:
+				status = rpFailed
+			case test.SkipMessage != nil:
+				status = rpSkipped
+			}
+			result, ok := byName[test.Name]
+			if !ok {
+				sig, subteam := parseDescribe(test.Name)
+				result = &reportResult{
+					name:     test.Name,
+					sig:      sig,
+					subteam:  subteam,
+					metadata: metadataFromName(test.Name),
+					status:   status,
+				}
+				byName[test.Name] = result
+				results = append(results, result)
+			} else if result.status != status && result.status != rpSkipped && status != rpSkipped {
+				// a failing and a passing result with the same name is a flake
+				result.flake = true
+				result.status = rpPassed
+			}
+			if test.Duration > result.duration {
+				result.duration = test.Duration
+			}
+			if test.FailureOutput != nil {
+				result.failure = strings.TrimSpace(test.FailureOutput.Message + "\n" + test.FailureOutput.Output)
+			}
+			if len(test.SystemOut) > 0 {
+				result.output = test.SystemOut
+			}
+		}
+		for _, child := range suite.Children {
+			add(child)
+		}
+	}
+	for _, suite := range suites {
+		add(suite)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].sig != results[j].sig {
+			return results[i].sig < results[j].sig
+		}
+		return results[i].subteam < results[j].subteam
+	})
+	duration := time.Duration(seconds * float64(time.Second))
+	for _, result := range results {
+		if d := time.Duration(result.duration * float64(time.Second)); d > duration {
+			duration = d
+		}
+	}
+	return results, monitor, duration
+}
+
+// readMonitorTimeline returns the monitor timeline written to the directory of the
+// first report that has one, or an empty string if none of them do.
+func readMonitorTimeline(reports []string) (string, error) {
+	for _, path := range reports {
+		data, err := ioutil.ReadFile(filepath.Join(filepath.Dir(path), monitorTimelineFile))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", nil
+}
+
+// uploadReportResults creates an item for every sig containing an item for every
+// subteam, with a step for each test below it. Tests that have no sig are grouped
+// under "ungrouped".
+func uploadReportResults(client *reportPortalClient, launch string, start time.Time, results []*reportResult, monitor *JUnitTestCase) error {
+	if monitor != nil {
+		if len(monitor.SystemOut) > 0 {
+			if err := client.Log(&rpLogRequest{LaunchUUID: launch, Time: rpTime(start), Message: monitor.SystemOut, Level: rpInfo}); err != nil {
+				return err
+			}
+		}
+		if monitor.FailureOutput != nil {
+			if err := client.Log(&rpLogRequest{LaunchUUID: launch, Time: rpTime(start), Message: monitor.FailureOutput.Output, Level: rpError}); err != nil {
+				return err
+			}
+		}
+	}
+
+	var sigItem, subteamItem, sig, subteam string
+	var sigEnd, subteamEnd time.Time
+	finishGroups := func(all bool) error {
+		if len(subteamItem) > 0 {
+			if err := client.FinishItem(subteamItem, &rpFinishRequest{EndTime: rpTime(subteamEnd), LaunchUUID: launch}); err != nil {
+				return err
+			}
+			subteamItem = ""
+		}
+		if all && len(sigItem) > 0 {
+			if err := client.FinishItem(sigItem, &rpFinishRequest{EndTime: rpTime(sigEnd), LaunchUUID: launch}); err != nil {
+				return err
+			}
+			sigItem = ""
+		}
+		return nil
+	}
+
+	for _, result := range results {
+		resultSig := result.sig
+		if len(resultSig) == 0 {
+			resultSig = "ungrouped"
+		}
+		if len(sigItem) == 0 || resultSig != sig {
+			if err := finishGroups(true); err != nil {
+				return err
+			}
+			id, err := client.StartItem("", &rpStartRequest{Name: resultSig, StartTime: rpTime(start), Type: "SUITE", LaunchUUID: launch})
+			if err != nil {
+				return err
+			}
+			sigItem, sig, sigEnd, subteam = id, resultSig, start, ""
+		}
+		parent := sigItem
+		if len(result.subteam) > 0 {
+			if len(subteamItem) == 0 || result.subteam != subteam {
+				if err := finishGroups(false); err != nil {
+					return err
+				}
+				id, err := client.StartItem(sigItem, &rpStartRequest{Name: result.subteam, StartTime: rpTime(start), Type: "TEST", LaunchUUID: launch})
+				if err != nil {
+					return err
+				}
+				subteamItem, subteam, subteamEnd = id, result.subteam, start
+			}
+			parent = subteamItem
+		} else if err := finishGroups(false); err != nil {
+			return err
+		}
+
+		end := start.Add(time.Duration(result.duration * float64(time.Second)))
+		if end.After(sigEnd) {
+			sigEnd = end
+		}
+		if end.After(subteamEnd) {
+			subteamEnd = end
+		}
+		if err := uploadReportResult(client, launch, parent, start, end, result); err != nil {
+			return err
+		}
+	}
+	return finishGroups(true)
+}
+
+func uploadReportResult(client *reportPortalClient, launch, parent string, start, end time.Time, result *reportResult) error {
+	item, err := client.StartItem(parent, &rpStartRequest{
+		Name:       result.name,
+		StartTime:  rpTime(start),
+		Type:       "STEP",
+		LaunchUUID: launch,
+		Attributes: reportAttributes(result),
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.failure) > 0 {
+		level := rpError
+		if result.flake {
+			level = rpWarn
+		}
+		if err := client.Log(&rpLogRequest{LaunchUUID: launch, ItemUUID: item, Time: rpTime(start), Message: result.failure, Level: level}); err != nil {
+			return err
+		}
+	}
+	if result.status != rpPassed && len(result.output) > 0 {
+		if err := client.Log(&rpLogRequest{LaunchUUID: launch, ItemUUID: item, Time: rpTime(start), Message: result.output, Level: rpInfo}); err != nil {
+			return err
+		}
+	}
+	finish := &rpFinishRequest{EndTime: rpTime(end), Status: result.status, LaunchUUID: launch}
+	if result.status == rpFailed {
+		finish.Issue = &rpIssue{IssueType: rpToInvestigate}
+	}
+	return client.FinishItem(item, finish)
+}
+
+// reportAttributes tags an item with the metadata from the test title.
+func reportAttributes(result *reportResult) []rpAttribute {
+	var attrs []rpAttribute
+	if len(result.metadata.Importance) > 0 {
+		attrs = append(attrs, rpAttribute{Key: "importance", Value: result.metadata.Importance})
+	}
+	if len(result.metadata.Author) > 0 {
+		attrs = append(attrs, rpAttribute{Key: "author", Value: result.metadata.Author})
+	}
+	for _, id := range result.metadata.CaseIDs {
+		attrs = append(attrs, rpAttribute{Key: "caseid", Value: id})
+	}
+	if result.flake {
+		attrs = append(attrs, rpAttribute{Value: "flake"})
+	}
+	return attrs
+}
@@ -0,0 +1,175 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// reportPortalStub records the requests made to the ReportPortal API.
+type reportPortalStub struct {
+	lock     sync.Mutex
+	next     int
+	requests []string
+	items    map[string]*rpStartRequest
+	parents  map[string]string
+	finished map[string]*rpFinishRequest
+	logs     []*rpLogRequest
+}
+
+func (s *reportPortalStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if r.Header.Get("Authorization") != "bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/ocp")
+	s.requests = append(s.requests, r.Method+" "+path)
+	switch {
+	case r.Method == http.MethodPost && path == "/log":
+		req := &rpLogRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		s.logs = append(s.logs, req)
+		fmt.Fprint(w, `{"id":"log"}`)
+	case r.Method == http.MethodPost:
+		req := &rpStartRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		s.next++
+		id := fmt.Sprintf("%s-%d", req.Type, s.next)
+		s.items[id] = req
+		if strings.HasPrefix(path, "/item/") {
+			s.parents[id] = s.items[strings.TrimPrefix(path, "/item/")].Name
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":%q}`, id)
+	case r.Method == http.MethodPut:
+		req := &rpFinishRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		id := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(path, "/item/"), "/launch/"), "/finish")
+		s.finished[id] = req
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+func TestReportUploadOptions_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suite := &JUnitTestSuite{
+		Name:     "openshift-tests-private",
+		Duration: 600,
+		TestCases: []*JUnitTestCase{
+			{Name: "[sig-node] NODE Probe Author:minmli-High-41579-Liveness probe [Serial]", Duration: 60},
+			{Name: "[sig-node] NODE Probe Author:minmli-Low-41580-Startup probe", Duration: 10, FailureOutput: &FailureOutput{Output: "fail [probe.go:1]: boom"}, SystemOut: "STEP: probe"},
+			{Name: "[sig-node] NODE Probe Author:minmli-Low-41580-Startup probe", Duration: 12},
+			{Name: "[sig-networking] SDN Author:zzhao-Medium-25321-dpdk", Duration: 5, FailureOutput: &FailureOutput{Output: "fail [dpdk.go:1]: boom"}},
+			{Name: "[sig-apps] Workloads Author:yinzhou-Critical-1-scaled", SkipMessage: &SkipMessage{Message: "skip [x.go:1]: no"}},
+		},
+	}
+	data, err := xml.Marshal(suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "junit_e2e.xml")
+	if err := ioutil.WriteFile(path, data, 0640); err != nil {
+		t.Fatal(err)
+	}
+	// the suite had no monitor errors, so the timeline is only in the JUnit directory
+	if err := ioutil.WriteFile(filepath.Join(dir, monitorTimelineFile), []byte("Timeline:\n\nnode became ready"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	stub := &reportPortalStub{items: map[string]*rpStartRequest{}, parents: map[string]string{}, finished: map[string]*rpFinishRequest{}}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	out := &strings.Builder{}
+	opt := &ReportUploadOptions{
+		ReportPortalURL: server.URL + "/api",
+		Project:         "ocp",
+		Token:           "secret",
+		Launch:          "4.10-aws",
+		Attributes:      []string{"platform:aws"},
+		Out:             out,
+		ErrOut:          ioutil.Discard,
+	}
+	if err := opt.Run([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Uploaded 4 tests") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	var launch *rpStartRequest
+	steps := make(map[string]string)
+	status := make(map[string]*rpFinishRequest)
+	var groups []string
+	for id, item := range stub.items {
+		switch item.Type {
+		case "":
+			launch = item
+		case "STEP":
+			steps[item.Name] = stub.parents[id]
+			status[item.Name] = stub.finished[id]
+		default:
+			groups = append(groups, stub.parents[id]+"/"+item.Name)
+		}
+		if _, ok := stub.finished[id]; !ok {
+			t.Errorf("item %s was not finished", item.Name)
+		}
+	}
+	if launch == nil || launch.Name != "4.10-aws" || !reflect.DeepEqual(launch.Attributes, []rpAttribute{{Key: "platform", Value: "aws"}}) {
+		t.Fatalf("unexpected launch: %#v", launch)
+	}
+	if len(groups) != 6 {
+		t.Errorf("expected a sig and subteam item for each of the three sigs, got %v", groups)
+	}
+	if steps["[sig-node] NODE Probe Author:minmli-High-41579-Liveness probe [Serial]"] != "NODE" || steps["[sig-networking] SDN Author:zzhao-Medium-25321-dpdk"] != "SDN" {
+		t.Errorf("tests were not grouped by subteam: %v", steps)
+	}
+
+	flake := status["[sig-node] NODE Probe Author:minmli-Low-41580-Startup probe"]
+	if flake.Status != rpPassed || flake.Issue != nil {
+		t.Errorf("expected flake to pass: %#v", flake)
+	}
+	if failed := status["[sig-networking] SDN Author:zzhao-Medium-25321-dpdk"]; failed.Status != rpFailed || failed.Issue == nil {
+		t.Errorf("expected failure to be reported: %#v", failed)
+	}
+	if skipped := status["[sig-apps] Workloads Author:yinzhou-Critical-1-scaled"]; skipped.Status != rpSkipped {
+		t.Errorf("expected test to be skipped: %#v", skipped)
+	}
+	for id, item := range stub.items {
+		if item.Name == "[sig-node] NODE Probe Author:minmli-High-41579-Liveness probe [Serial]" {
+			want := []rpAttribute{{Key: "importance", Value: "High"}, {Key: "author", Value: "minmli"}, {Key: "caseid", Value: "41579"}}
+			if !reflect.DeepEqual(item.Attributes, want) {
+				t.Errorf("unexpected attributes for %s: %v", id, item.Attributes)
+			}
+		}
+	}
+
+	var timeline, failure bool
+	for _, log := range stub.logs {
+		switch {
+		case len(log.ItemUUID) == 0 && strings.Contains(log.Message, "node became ready"):
+			timeline = true
+		case log.Level == rpError && strings.Contains(log.Message, "dpdk.go"):
+			failure = true
+		}
+	}
+	if !timeline || !failure {
+		t.Errorf("expected the monitor timeline and the failure output to be logged: %#v", stub.logs)
+	}
+}
@@ -10,32 +10,110 @@ import (
 	"os/signal"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/openshift/openshift-tests-private/pkg/monitor"
+	"github.com/openshift/openshift-tests-private/test/extended/util/upgradestate"
 
 	"github.com/onsi/ginkgo/config"
 )
 
+// suiteDeadlineMessage is the skip message of tests that were not started before
+// the suite timeout.
+const suiteDeadlineMessage = "suite deadline reached"
+
 // Options is used to run a suite of tests by invoking each test
 // as a call to a child worker (the run-tests command).
 type Options struct {
 	Parallelism int
 	Count       int
 	Timeout     time.Duration
-	JUnitDir    string
-	TestFile    string
-	OutFile     string
-	Regex       string
+	// SuiteTimeout is the time after which no more tests are started. Tests
+	// that were not started are reported as skipped.
+	SuiteTimeout time.Duration
+	JUnitDir     string
+	// JUnitBySubteam writes the tests of each subteam to a nested suite of the
+	// JUnit report.
+	JUnitBySubteam bool
+	TestFile       string
+	OutFile        string
+	Regex          string
+
+	// Filter selects tests by the metadata in their titles, in addition to Regex.
+	Filter MetadataFilter
+	// Owners resolves each test to the approvers and reviewers of its nearest
+	// OWNERS file. The tests whose title names an author that is not one of them
+	// are reported by DryRun.
+	Owners *OwnersIndex
+	// Owner only selects the tests this GitHub id is an approver or reviewer of.
+	Owner string
+	// ClusterSkips returns the label that keeps the named test from running on the
+	// cluster, or an empty string. Tests it returns a label for are excluded from the
+	// shard before scheduling. It is not called by DryRun or PrintCommands, describes
+	// the default cluster only and is not used with Kubeconfigs.
+	ClusterSkips func(name string) string
 
 	IncludeSuccessOutput bool
 
+	// RepeatUntilFailure runs the tests again until a round of them has a failure,
+	// and Soak until the duration has passed. Either reports the pass rate of each
+	// test at the end and keeps the output of the first SoakFailureOutputs failures
+	// of each test.
+	RepeatUntilFailure bool
+	Soak               time.Duration
+	SoakFailureOutputs int
+
+	// ShardCount and ShardIndex select a deterministic subset of the suite so it
+	// can be split across several runners.
+	ShardCount int
+	ShardIndex int
+	// DurationsFile holds the durations of a previous run, as a JUnit report or a
+	// JSON object of test name to seconds. It is used to balance shards and to
+	// start the longest tests first.
+	DurationsFile string
+
+	// OutputFormat is "text" or "ndjson". With ndjson a JSON object is written for
+	// each lifecycle event to EventsFile, or to stdout if it is empty or "-", in
+	// addition to the text output.
+	OutputFormat string
+	EventsFile   string
+
+	// QuarantineFile lists tests whose failures are reported as flakes and do not
+	// fail the suite.
+	QuarantineFile string
+
+	// Preflight checks the health of the cluster before any test is started and
+	// reports it as a test. With PreflightAbort the run stops if the cluster is not
+	// healthy.
+	Preflight string
+
+	// HistoryDB is the path of a SQLite database the results of the run are
+	// recorded to.
+	HistoryDB string
+
+	// Resume skips the tests recorded as passed or skipped in the checkpoint of a
+	// previous run in JUnitDir and merges their results into the report.
+	Resume bool
+
 	Provider     string
 	SuiteOptions string
 
+	// Kubeconfigs runs the suite against each of these clusters at the same time,
+	// with a worker pool and a monitor per cluster, and aggregates the results.
+	Kubeconfigs []string
+	// kubeconfig is the cluster a single run of the suite is against. The
+	// default kube configuration is used if it is empty.
+	kubeconfig string
+	// upgradeStateDir is passed to upgrade checks as upgradestate.DirEnv.
+	upgradeStateDir string
+
 	Suites []*TestSuite
+	// SuiteFile is a YAML suite definition, or a directory of them, that adds to
+	// or replaces the suites in Suites.
+	SuiteFile string
 
 	DryRun        bool
 	PrintCommands bool
@@ -46,12 +124,71 @@ func (opt *Options) AsEnv() []string {
 	var args []string
 	args = append(args, fmt.Sprintf("TEST_PROVIDER=%s", opt.Provider))
 	args = append(args, fmt.Sprintf("TEST_SUITE_OPTIONS=%s", opt.SuiteOptions))
+	if len(opt.kubeconfig) > 0 {
+		args = append(args, fmt.Sprintf("KUBECONFIG=%s", opt.kubeconfig))
+	}
+	if len(opt.upgradeStateDir) > 0 {
+		args = append(args, fmt.Sprintf("%s=%s", upgradestate.DirEnv, opt.upgradeStateDir))
+	}
 	return args
 }
 
+// junitProperties returns the properties recorded on the JUnit test suite: the
+// cluster the suite ran against, if known, and the options of the run.
+func (opt *Options) junitProperties(suite string, parallelism int, timeout time.Duration, cluster *clusterInfo) []*TestSuiteProperty {
+	var properties []*TestSuiteProperty
+	add := func(name, value string) {
+		if len(value) > 0 {
+			properties = append(properties, &TestSuiteProperty{Name: name, Value: value})
+		}
+	}
+	if cluster != nil {
+		add("cluster-version", cluster.Version)
+		add("platform", cluster.Platform)
+		add("network-type", cluster.NetworkType)
+	}
+	add("suite", suite)
+	add("parallelism", strconv.Itoa(parallelism))
+	add("timeout", timeout.String())
+	if opt.Count > 0 {
+		add("count", strconv.Itoa(opt.Count))
+	}
+	add("run", opt.Regex)
+	add("importance", strings.Join(opt.Filter.Importance, ","))
+	add("author", strings.Join(opt.Filter.Authors, ","))
+	add("case-id", strings.Join(opt.Filter.CaseIDs, ","))
+	add("exclude-prefix", strings.Join(opt.Filter.ExcludePrefixes, ","))
+	add("owner", opt.Owner)
+	if opt.ShardCount > 1 {
+		add("shard-index", strconv.Itoa(opt.ShardIndex))
+		add("shard-count", strconv.Itoa(opt.ShardCount))
+	}
+	if opt.RepeatUntilFailure {
+		add("repeat-until-failure", "true")
+	}
+	if opt.Soak > 0 {
+		add("soak", opt.Soak.String())
+	}
+	add("quarantine", opt.QuarantineFile)
+	if opt.Resume {
+		add("resume", "true")
+	}
+	add("provider", opt.Provider)
+	add("options", opt.SuiteOptions)
+	return properties
+}
+
 func (opt *Options) Run(args []string) error {
 	var suite *TestSuite
 
+	if len(opt.SuiteFile) > 0 {
+		suites, err := loadSuiteFiles(opt.SuiteFile)
+		if err != nil {
+			return err
+		}
+		opt.Suites = mergeSuites(opt.Suites, suites)
+	}
+
 	if len(opt.TestFile) > 0 {
 		var in []byte
 		var err error
@@ -95,6 +232,60 @@ func (opt *Options) Run(args []string) error {
 		}
 	}
 
+	if err := opt.Filter.Validate(); err != nil {
+		return err
+	}
+	if len(opt.Owner) > 0 && opt.Owners == nil {
+		return fmt.Errorf("--owner requires the OWNERS files of the tests")
+	}
+	if err := validateShard(opt.ShardCount, opt.ShardIndex); err != nil {
+		return err
+	}
+	switch opt.Preflight {
+	case "", PreflightReport, PreflightAbort:
+	default:
+		return fmt.Errorf("--preflight must be one of %s or %s", PreflightReport, PreflightAbort)
+	}
+	switch opt.OutputFormat {
+	case "", "text", "ndjson":
+	default:
+		return fmt.Errorf("--output-format must be one of text or ndjson")
+	}
+	if opt.OutputFormat == "ndjson" && len(opt.Kubeconfigs) > 0 {
+		return fmt.Errorf("--output-format=ndjson may not be combined with --kubeconfigs")
+	}
+	if opt.Soak < 0 {
+		return fmt.Errorf("--soak may not be negative")
+	}
+	if opt.RepeatUntilFailure || opt.Soak > 0 {
+		switch {
+		case len(opt.Kubeconfigs) > 0:
+			return fmt.Errorf("--repeat-until-failure and --soak may not be combined with --kubeconfigs")
+		case opt.Resume:
+			return fmt.Errorf("--repeat-until-failure and --soak may not be combined with --resume")
+		case opt.OutputFormat == "ndjson":
+			return fmt.Errorf("--repeat-until-failure and --soak may not be combined with --output-format=ndjson")
+		case opt.SoakFailureOutputs < 0:
+			return fmt.Errorf("--soak-failure-outputs may not be negative")
+		}
+	}
+	var quarantined *quarantine
+	if len(opt.QuarantineFile) > 0 {
+		var err error
+		quarantined, err = loadQuarantine(opt.QuarantineFile, time.Now(), opt.ErrOut)
+		if err != nil {
+			return err
+		}
+	}
+	var durations map[string]time.Duration
+	if len(opt.DurationsFile) > 0 {
+		var err error
+		durations, err = loadDurations(opt.DurationsFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	tests, err := testsForSuite(config.GinkgoConfig)
 	if err != nil {
 		return err
@@ -116,10 +307,40 @@ func (opt *Options) Run(args []string) error {
 		return false
 	})
 
-	tests = suite.Filter(tests)
+	tests = opt.Filter.Filter(suite.Filter(tests))
+	if opt.Owners != nil {
+		if err := setOwners(tests, opt.Owners); err != nil {
+			return err
+		}
+	}
+	if len(opt.Owner) > 0 {
+		tests = filterByOwner(tests, opt.Owner)
+	}
 	if len(tests) == 0 {
 		return fmt.Errorf("suite %q does not contain any tests", suite.Name)
 	}
+	if opt.ShardCount > 1 {
+		tests = shardTests(tests, opt.ShardCount, opt.ShardIndex, durations)
+		if len(tests) == 0 {
+			fmt.Fprintf(opt.Out, "Shard %d of %d does not contain any tests\n", opt.ShardIndex, opt.ShardCount)
+			return nil
+		}
+	}
+	// shards may run against clusters that can run different tests, so the tests are
+	// only excluded once every shard has split the same suite, and only when they are
+	// about to run so that listing them does not need a cluster
+	var clusterSkipped []*testCase
+	if opt.ClusterSkips != nil && len(opt.Kubeconfigs) == 0 && !opt.DryRun && !opt.PrintCommands {
+		clusterSkipped, tests = splitTests(tests, func(t *testCase) bool {
+			return len(opt.ClusterSkips(t.name)) > 0
+		})
+	}
+
+	if quarantined != nil {
+		for _, test := range tests {
+			test.quarantine = quarantined.Lookup(test)
+		}
+	}
 
 	count := opt.Count
 	if count == 0 {
@@ -134,17 +355,40 @@ func (opt *Options) Run(args []string) error {
 	}
 
 	if opt.PrintCommands {
-		status := newTestStatus(opt.Out, true, len(tests), time.Minute, &monitor.Monitor{}, opt.AsEnv())
-		newParallelTestQueue(tests).Execute(context.Background(), 1, status.OutputCommand)
+		kubeconfigs := opt.Kubeconfigs
+		if len(kubeconfigs) == 0 {
+			kubeconfigs = []string{opt.kubeconfig}
+		}
+		for _, kubeconfig := range kubeconfigs {
+			clusterOpt := *opt
+			clusterOpt.kubeconfig = kubeconfig
+			status := newTestStatus(opt.Out, true, len(tests), time.Minute, &monitor.Monitor{}, clusterOpt.AsEnv())
+			newParallelTestQueue(tests, nil).Execute(context.Background(), 1, status.OutputCommand)
+		}
 		return nil
 	}
 	if opt.DryRun {
 		for _, test := range sortedTests(tests) {
 			fmt.Fprintf(opt.Out, "%q\n", test.name)
+			for _, warning := range test.metadata.Warnings {
+				fmt.Fprintf(opt.ErrOut, "warning: %s: %q\n", warning, test.name)
+			}
 		}
 		return nil
 	}
+	if len(clusterSkipped) > 0 {
+		for _, test := range sortedTests(clusterSkipped) {
+			fmt.Fprintf(opt.ErrOut, "skipped: cannot run on this cluster (%s): %q\n", opt.ClusterSkips(test.name), test.name)
+		}
+		fmt.Fprintf(opt.Out, "Excluded %d tests that cannot run on this cluster\n\n", len(clusterSkipped))
+		if len(tests) == 0 {
+			return nil
+		}
+	}
 
+	if opt.Resume && len(opt.JUnitDir) == 0 {
+		return fmt.Errorf("--resume requires --junit-dir")
+	}
 	if len(opt.JUnitDir) > 0 {
 		if _, err := os.Stat(opt.JUnitDir); err != nil {
 			if !os.IsNotExist(err) {
@@ -164,6 +408,12 @@ func (opt *Options) Run(args []string) error {
 		parallelism = 3
 	}
 	timeout := opt.Timeout
+	if timeout > 0 {
+		// an explicit timeout takes precedence over the [Timeout:<duration>] labels
+		for _, test := range tests {
+			test.timeout = 0
+		}
+	}
 	if timeout == 0 {
 		timeout = suite.TestTimeout
 	}
@@ -173,13 +423,15 @@ func (opt *Options) Run(args []string) error {
 
 	ctx, cancelFn := context.WithCancel(context.Background())
 	defer cancelFn()
-	abortCh := make(chan os.Signal)
+	interrupted := &interruptHandlers{}
+	abortCh := make(chan os.Signal, 2)
 	go func() {
 		<-abortCh
 		fmt.Fprintf(opt.ErrOut, "Interrupted, terminating tests\n")
 		cancelFn()
 		sig := <-abortCh
 		fmt.Fprintf(opt.ErrOut, "Interrupted twice, exiting (%s)\n", sig)
+		interrupted.Run()
 		switch sig {
 		case syscall.SIGINT:
 			os.Exit(130)
@@ -189,42 +441,214 @@ func (opt *Options) Run(args []string) error {
 	}()
 	signal.Notify(abortCh, syscall.SIGINT, syscall.SIGTERM)
 
-	m, err := monitor.Start(ctx)
+	if len(opt.Kubeconfigs) > 0 {
+		return opt.runClusters(ctx, interrupted, suite, tests, durations, parallelism, timeout)
+	}
+	if opt.RepeatUntilFailure || opt.Soak > 0 {
+		return opt.runSoak(ctx, suite, tests, durations, parallelism, timeout)
+	}
+	_, err = opt.runSuite(ctx, interrupted, suite, tests, durations, parallelism, timeout)
+	return err
+}
+
+// suiteResult holds the results of a run of a suite against one cluster.
+type suiteResult struct {
+	tests      []*testCase
+	synthetic  []*JUnitTestCase
+	properties []*TestSuiteProperty
+	duration   time.Duration
+	flakes     map[string]struct{}
+}
+
+// runSuite runs tests against the cluster of opt.kubeconfig and reports the
+// results. The result is returned even if the suite failed, unless the tests could
+// not be run at all.
+func (opt *Options) runSuite(ctx context.Context, interrupted *interruptHandlers, suite *TestSuite, tests []*testCase, durations map[string]time.Duration, parallelism int, timeout time.Duration) (*suiteResult, error) {
+	// describe the cluster before the tests change it
+	var cluster *clusterInfo
+	if len(opt.JUnitDir) > 0 || len(opt.HistoryDB) > 0 {
+		cluster, _ = discoverClusterInfo(opt.kubeconfig)
+	}
+	properties := opt.junitProperties(suite.Name, parallelism, timeout, cluster)
+	out := opt.Out
+
+	var resumed []*testCase
+	if opt.Resume {
+		records, err := readCheckpoint(opt.JUnitDir, opt.ErrOut)
+		if err != nil {
+			return nil, fmt.Errorf("could not read checkpoint: %v", err)
+		}
+		tests, resumed = resumeTests(tests, records)
+		fmt.Fprintf(out, "Resuming previous run, %d tests already passed or skipped\n\n", len(resumed))
+	}
+
+	var events *eventWriter
+	if opt.OutputFormat == "ndjson" {
+		var eventsOut io.Writer
+		switch opt.EventsFile {
+		case "", "-":
+			// keep the event stream parseable by moving the text output to stderr
+			eventsOut, out = os.Stdout, opt.ErrOut
+		default:
+			f, err := os.OpenFile(opt.EventsFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+			if err != nil {
+				return nil, fmt.Errorf("could not open --events-file: %v", err)
+			}
+			defer f.Close()
+			eventsOut = f
+		}
+		var outputDir string
+		if len(opt.JUnitDir) > 0 {
+			outputDir = filepath.Join(opt.JUnitDir, "test-output")
+		}
+		var err error
+		events, err = newEventWriter(eventsOut, outputDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// record each finished test so an interrupted run can be reported and resumed
+	var cp *checkpoint
+	suiteStart := time.Now()
+	if len(opt.JUnitDir) > 0 {
+		var err error
+		cp, err = openCheckpoint(opt.JUnitDir, opt.Resume)
+		if err != nil {
+			return nil, fmt.Errorf("could not open checkpoint: %v", err)
+		}
+		defer cp.Close()
+		interrupted.Add(func() {
+			partial := append(append([]*testCase{}, resumed...), cp.Tests()...)
+			report := newJUnitSuite("openshift-tests-private", partial, time.Now().Sub(suiteStart), properties, opt.JUnitBySubteam)
+			if err := writeJUnitReport("junit_e2e", report, opt.JUnitDir, opt.ErrOut); err != nil {
+				fmt.Fprintf(opt.ErrOut, "error: Unable to write e2e JUnit results: %v\n", err)
+			}
+		})
+	}
+
+	m, err := monitor.StartWithKubeconfig(ctx, opt.kubeconfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
+
+	var syntheticTestResults []*JUnitTestCase
+	if len(opt.Preflight) > 0 {
+		result, problems := runPreflight(ctx, m)
+		syntheticTestResults = append(syntheticTestResults, result)
+		if len(problems) > 0 {
+			fmt.Fprintf(out, "Preflight check found the cluster unhealthy:\n\n%s\n\n", strings.Join(problems, "\n"))
+			if opt.Preflight == PreflightAbort {
+				if len(opt.JUnitDir) > 0 {
+					report := newJUnitSuite("openshift-tests-private", nil, time.Duration(result.Duration*float64(time.Second)), properties, opt.JUnitBySubteam, syntheticTestResults...)
+					if err := writeJUnitReport("junit_e2e", report, opt.JUnitDir, opt.ErrOut); err != nil {
+						fmt.Fprintf(out, "error: Unable to write e2e JUnit results: %v", err)
+					}
+				}
+				aborted := &suiteResult{synthetic: syntheticTestResults, properties: properties, duration: time.Duration(result.Duration * float64(time.Second))}
+				return aborted, fmt.Errorf("preflight check failed, the cluster was not healthy before the suite started: %s", strings.Join(problems, "; "))
+			}
+		} else {
+			fmt.Fprintf(out, "Preflight check passed\n\n")
+		}
+	}
+	// pre-upgrade checks run before the upgrade and post-upgrade checks after it
+	checks := splitUpgradeChecks(tests)
+	if len(checks.upgrade) > 0 && len(checks.pre)+len(checks.post) > 0 && len(opt.upgradeStateDir) == 0 {
+		dir, err := upgradeStateDir(opt.JUnitDir)
+		if err != nil {
+			return nil, fmt.Errorf("could not create the upgrade state store: %v", err)
+		}
+		opt.upgradeStateDir = dir
+		fmt.Fprintf(out, "Upgrade checks pass state through %s\n\n", dir)
+	}
+
 	// if we run a single test, always include success output
 	includeSuccess := opt.IncludeSuccessOutput
 	if len(tests) == 1 {
 		includeSuccess = true
 	}
-	status := newTestStatus(opt.Out, includeSuccess, len(tests), timeout, m, opt.AsEnv())
-
-	smoke, normal := splitTests(tests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Smoke]")
-	})
+	var diag *diagnostics
+	if len(opt.JUnitDir) > 0 {
+		diag = newDiagnostics(opt.JUnitDir)
+	}
+	status := newTestStatus(out, includeSuccess, len(tests), timeout, m, opt.AsEnv())
+	status.events = events
+	status.diagnostics = diag
+	events.SuiteStarted(suite.Name, len(tests))
+	events.StreamMonitor(ctx, m)
+	run := status.Run
+	if cp != nil {
+		run = cp.Wrap(status.Run, func(err error) {
+			fmt.Fprintf(opt.ErrOut, "error: Unable to write checkpoint: %v\n", err)
+		})
+	}
 
 	// run the tests
 	start := time.Now()
 
-	// run our smoke tests first
-	q := newParallelTestQueue(smoke)
-	q.Execute(ctx, parallelism, status.Run)
+	var deadline time.Time
+	if opt.SuiteTimeout > 0 {
+		deadline = suiteStart.Add(opt.SuiteTimeout)
+	}
+
+	execute := func(tests []*testCase) {
+		smoke, normal := splitTests(tests, func(t *testCase) bool {
+			return strings.Contains(t.name, "[Smoke]")
+		})
 
-	// run other tests next
-	q = newParallelTestQueue(normal)
-	q.Execute(ctx, parallelism, status.Run)
+		// run our smoke tests first
+		q := newParallelTestQueue(smoke, durations)
+		q.deadline = deadline
+		q.Execute(ctx, parallelism, run)
+
+		// run other tests next
+		q = newParallelTestQueue(normal, durations)
+		q.deadline = deadline
+		q.Execute(ctx, parallelism, run)
+	}
+	if len(checks.upgrade) > 0 {
+		checks.Run(execute)
+	} else {
+		execute(tests)
+	}
+
+	// tests that were not started before the deadline are reported as skipped
+	var unscheduled int
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		for _, test := range tests {
+			if test.success || test.failed || test.skipped {
+				continue
+			}
+			test.skipped = true
+			test.out = []byte(suiteDeadlineMessage)
+			unscheduled++
+		}
+		if unscheduled > 0 {
+			fmt.Fprintf(out, "Suite timeout of %s reached, %d tests were not started\n\n", opt.SuiteTimeout, unscheduled)
+		}
+	}
 
 	duration := time.Now().Sub(start).Round(time.Second / 10)
 	if duration > time.Minute {
 		duration = duration.Round(time.Second)
 	}
 
+	// merge the results of a previous run, but only record the tests that ran in
+	// this one to the history so that resuming does not count a result twice
+	ran := tests
+	tests = append(resumed, tests...)
+
 	pass, fail, skip, failing := summarizeTests(tests)
 
+	// failures of quarantined tests are reported as flakes and do not fail the suite
+	quarantinedFailures, failing := splitTests(failing, func(t *testCase) bool {
+		return t.quarantine != nil
+	})
+	unexpected := fail - len(quarantinedFailures)
+
 	// monitor the cluster while the tests are running and report any detected
 	// anomalies
-	var syntheticTestResults []*JUnitTestCase
 	if events := m.Events(time.Time{}, time.Time{}); len(events) > 0 {
 		buf, errBuf := &bytes.Buffer{}, &bytes.Buffer{}
 		fmt.Fprintf(buf, "\nTimeline:\n\n")
@@ -266,7 +690,7 @@ func (opt *Options) Run(args []string) error {
 
 		if errorCount > 0 {
 			syntheticTestResults = append(syntheticTestResults, &JUnitTestCase{
-				Name:      "Monitor cluster while tests execute",
+				Name:      monitorTestName,
 				SystemOut: buf.String(),
 				Duration:  duration.Seconds(),
 				FailureOutput: &FailureOutput{
@@ -274,28 +698,48 @@ func (opt *Options) Run(args []string) error {
 				},
 			})
 		}
+		// the timeline is kept next to the reports so that it can be attached to
+		// uploaded results
+		if len(opt.JUnitDir) > 0 {
+			if err := ioutil.WriteFile(filepath.Join(opt.JUnitDir, monitorTimelineFile), buf.Bytes(), 0640); err != nil {
+				fmt.Fprintf(opt.ErrOut, "error: Unable to write monitor timeline: %v\n", err)
+			}
+		}
 
-		opt.Out.Write(buf.Bytes())
+		out.Write(buf.Bytes())
 	}
 
 	// attempt to retry failures to do flake detection
-	if fail > 0 && fail <= suite.MaximumAllowedFlakes {
+	flakes := make(map[string]struct{})
+	for _, test := range quarantinedFailures {
+		flakes[test.name] = struct{}{}
+	}
+	if unexpected > 0 && unexpected <= suite.MaximumAllowedFlakes && unscheduled == 0 {
 		var retries []*testCase
 		for _, test := range failing {
+			// the cluster no longer is in the state upgrade steps expect
+			if len(checks.upgrade) > 0 && upgradePhaseOf(test.name, &test.metadata) != notUpgradePhase {
+				continue
+			}
+			events.FlakeRetry(test)
 			retries = append(retries, test.Retry())
 			if len(retries) > suite.MaximumAllowedFlakes {
 				break
 			}
 		}
 
-		q := newParallelTestQueue(retries)
+		q := newParallelTestQueue(retries, durations)
+		q.deadline = deadline
 		status := newTestStatus(ioutil.Discard, opt.IncludeSuccessOutput, len(retries), timeout, m, opt.AsEnv())
+		status.events = events
+		status.diagnostics = diag
 		q.Execute(ctx, parallelism, status.Run)
 		var flaky []string
 		var repeatFailures []*testCase
 		for _, test := range retries {
 			if test.success {
 				flaky = append(flaky, test.name)
+				flakes[test.name] = struct{}{}
 			} else {
 				repeatFailures = append(repeatFailures, test)
 			}
@@ -303,29 +747,52 @@ func (opt *Options) Run(args []string) error {
 		if len(flaky) > 0 {
 			failing = repeatFailures
 			sort.Strings(flaky)
-			fmt.Fprintf(opt.Out, "Flaky tests:\n\n%s\n\n", strings.Join(flaky, "\n"))
+			fmt.Fprintf(out, "Flaky tests:\n\n%s\n\n", strings.Join(flaky, "\n"))
 		}
 	}
 
+	if len(quarantinedFailures) > 0 {
+		var lines []string
+		for _, test := range quarantinedFailures {
+			lines = append(lines, fmt.Sprintf("%s (%s)", test.name, test.quarantine))
+		}
+		sort.Strings(lines)
+		fmt.Fprintf(out, "Quarantined failing tests:\n\n%s\n\n", strings.Join(lines, "\n"))
+	}
+
 	if len(failing) > 0 {
 		names := testNames(failing)
 		sort.Strings(names)
-		fmt.Fprintf(opt.Out, "Failing tests:\n\n%s\n\n", strings.Join(names, "\n"))
+		fmt.Fprintf(out, "Failing tests:\n\n%s\n\n", strings.Join(names, "\n"))
+	}
+
+	if len(opt.HistoryDB) > 0 {
+		if err := recordHistory(opt.HistoryDB, suite.Name, start, duration, cluster, ran, flakes); err != nil {
+			fmt.Fprintf(opt.ErrOut, "error: Unable to record results to history database: %v\n", err)
+		}
 	}
 
 	if len(opt.JUnitDir) > 0 {
-		if err := writeJUnitReport("junit_e2e", "openshift-tests-private", tests, opt.JUnitDir, duration, opt.ErrOut, syntheticTestResults...); err != nil {
-			fmt.Fprintf(opt.Out, "error: Unable to write e2e JUnit results: %v", err)
+		report := newJUnitSuite("openshift-tests-private", tests, duration, properties, opt.JUnitBySubteam, syntheticTestResults...)
+		if err := writeJUnitReport("junit_e2e", report, opt.JUnitDir, opt.ErrOut); err != nil {
+			fmt.Fprintf(out, "error: Unable to write e2e JUnit results: %v", err)
 		}
 	}
 
-	if fail > 0 {
+	result := &suiteResult{tests: tests, synthetic: syntheticTestResults, properties: properties, duration: duration, flakes: flakes}
+	if unexpected > 0 {
 		if len(failing) > 0 || suite.MaximumAllowedFlakes == 0 {
-			return fmt.Errorf("%d fail, %d pass, %d skip (%s)", fail, pass, skip, duration)
+			return result, fmt.Errorf("%d fail, %d pass, %d skip (%s)", fail, pass, skip, duration)
 		}
-		fmt.Fprintf(opt.Out, "%d flakes detected, suite allows passing with only flakes\n\n", fail)
+		fmt.Fprintf(out, "%d flakes detected, suite allows passing with only flakes\n\n", unexpected)
+	}
+	if len(quarantinedFailures) > 0 {
+		fmt.Fprintf(out, "%d quarantined tests failed and were reported as flakes\n\n", len(quarantinedFailures))
+	}
+	if unscheduled > 0 {
+		return result, fmt.Errorf("suite timeout of %s reached, %d tests were not started: %d pass, %d skip (%s)", opt.SuiteTimeout, unscheduled, pass, skip, duration)
 	}
 
-	fmt.Fprintf(opt.Out, "%d pass, %d skip (%s)\n", pass, skip, duration)
-	return ctx.Err()
+	fmt.Fprintf(out, "%d pass, %d skip (%s)\n", pass, skip, duration)
+	return result, ctx.Err()
 }
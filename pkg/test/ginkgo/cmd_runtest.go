@@ -3,6 +3,7 @@ package ginkgo
 import (
 	"fmt"
 	"io"
+	"os"
 	"regexp"
 	"strings"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/onsi/ginkgo/config"
 	"github.com/onsi/ginkgo/reporters"
 	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/openshift-tests-private/test/extended/util/upgradestate"
 )
 
 type ExitError struct {
@@ -51,6 +54,14 @@ func (opt *TestOptions) Run(args []string) error {
 		return nil
 	}
 
+	// the test utilities read the key of an upgrade check rather than parse the title
+	switch upgradePhaseOf(test.name, &test.metadata) {
+	case preCheckPhase, postCheckPhase:
+		if key := upgradeCheckKey(test.name, &test.metadata); len(key) > 0 {
+			os.Setenv(upgradestate.CheckKeyEnv, key)
+		}
+	}
+
 	config.GinkgoConfig.FocusString = fmt.Sprintf("^%s$", regexp.QuoteMeta(" [Top Level] "+test.name))
 	config.DefaultReporterConfig.NoColor = true
 	w := ginkgo.GinkgoWriterType()
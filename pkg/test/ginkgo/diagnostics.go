@@ -0,0 +1,98 @@
+package ginkgo
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/openshift-tests-private/pkg/monitor"
+)
+
+// artifactsDir is the directory of the JUnit directory holding diagnostic bundles.
+const artifactsDir = "artifacts"
+
+// clusterOperatorLister is implemented by monitors that watch cluster operators.
+type clusterOperatorLister interface {
+	ClusterOperators() []*configv1.ClusterOperator
+}
+
+// diagnostics assigns each test run a bundle directory below junitDir, named by
+// the first case ID of the test or by its name if it has none.
+type diagnostics struct {
+	junitDir string
+
+	lock sync.Mutex
+	used map[string]int
+}
+
+func newDiagnostics(junitDir string) *diagnostics {
+	return &diagnostics{junitDir: junitDir, used: make(map[string]int)}
+}
+
+// Reserve returns the bundle directory of a run of test relative to the JUnit
+// directory. Each run of a test is given its own directory.
+func (d *diagnostics) Reserve(test *testCase) string {
+	id := unsafeFilenameCharacters.ReplaceAllString(test.name, "_")
+	if len(id) > 100 {
+		id = id[:100]
+	}
+	if len(test.metadata.CaseIDs) > 0 {
+		id = test.metadata.CaseIDs[0]
+	}
+	d.lock.Lock()
+	d.used[id]++
+	n := d.used[id]
+	d.lock.Unlock()
+	if n > 1 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return filepath.Join(artifactsDir, id)
+}
+
+// Path returns the absolute location of a bundle returned by Reserve.
+func (d *diagnostics) Path(bundle string) string {
+	return filepath.Join(d.junitDir, bundle)
+}
+
+// Write adds the monitor intervals recorded while test ran and the current
+// conditions of the cluster operators to the bundle of a failed test.
+func (d *diagnostics) Write(bundle string, test *testCase, m monitor.Interface) error {
+	dir := d.Path(bundle)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if m == nil {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	for _, event := range m.Events(test.start, test.end) {
+		fmt.Fprintln(buf, event.String())
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "monitor.log"), buf.Bytes(), 0640); err != nil {
+		return err
+	}
+	lister, ok := m.(clusterOperatorLister)
+	if !ok {
+		return nil
+	}
+	type operatorConditions struct {
+		Name       string                                    `json:"name"`
+		Conditions []configv1.ClusterOperatorStatusCondition `json:"conditions"`
+	}
+	var operators []operatorConditions
+	for _, co := range lister.ClusterOperators() {
+		operators = append(operators, operatorConditions{Name: co.Name, Conditions: co.Status.Conditions})
+	}
+	data, err := yaml.Marshal(operators)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "clusteroperators.yaml"), data, 0640)
+}
@@ -0,0 +1,91 @@
+package ginkgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/openshift-tests-private/pkg/monitor"
+)
+
+type fakeDiagnosticsMonitor struct {
+	events    monitor.EventIntervals
+	operators []*configv1.ClusterOperator
+}
+
+func (m *fakeDiagnosticsMonitor) Events(from, to time.Time) monitor.EventIntervals { return m.events }
+func (m *fakeDiagnosticsMonitor) Conditions(from, to time.Time) monitor.EventIntervals {
+	return nil
+}
+func (m *fakeDiagnosticsMonitor) ClusterOperators() []*configv1.ClusterOperator { return m.operators }
+
+func TestDiagnostics_Reserve(t *testing.T) {
+	d := newDiagnostics("/junit")
+	withID := &testCase{name: "[sig-node] NODE Author:minmli-High-41579-Liveness probe", metadata: TestMetadata{CaseIDs: []string{"41579"}}}
+	withoutID := &testCase{name: "[sig-node] a/b test"}
+	tests := []struct {
+		test *testCase
+		want string
+	}{
+		{test: withID, want: "artifacts/41579"},
+		{test: withID, want: "artifacts/41579-2"},
+		{test: withoutID, want: "artifacts/_sig-node_a_b_test"},
+	}
+	for _, tt := range tests {
+		if got := d.Reserve(tt.test); got != tt.want {
+			t.Errorf("Reserve(%q) = %q, want %q", tt.test.name, got, tt.want)
+		}
+	}
+	if got, want := d.Path("artifacts/41579"), "/junit/artifacts/41579"; got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestDiagnostics_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diagnostics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	m := &fakeDiagnosticsMonitor{
+		events: monitor.EventIntervals{
+			{Condition: &monitor.Condition{Level: monitor.Warning, Locator: "node/a", Message: "reboot"}, From: now, To: now},
+		},
+		operators: []*configv1.ClusterOperator{{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns"},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, Message: "dns pods crashing"},
+			}},
+		}},
+	}
+	d := newDiagnostics(dir)
+	test := &testCase{name: "test", start: now, end: now.Add(time.Minute), metadata: TestMetadata{CaseIDs: []string{"123"}}}
+	bundle := d.Reserve(test)
+	if err := d.Write(bundle, test, m); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "artifacts", "123", "monitor.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "node/a reboot") {
+		t.Errorf("unexpected monitor.log: %s", data)
+	}
+	data, err = ioutil.ReadFile(filepath.Join(dir, "artifacts", "123", "clusteroperators.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "name: dns") || !strings.Contains(string(data), "dns pods crashing") {
+		t.Errorf("unexpected clusteroperators.yaml: %s", data)
+	}
+}
@@ -0,0 +1,63 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// loadDurations reads the duration of each test from a JUnit report written by a
+// previous run, or from a JSON object mapping test names to seconds. If a test is
+// reported more than once the longest duration is kept.
+func loadDurations(path string) (map[string]time.Duration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	durations := make(map[string]time.Duration)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = durationsFromJSON(data, durations)
+	} else {
+		err = durationsFromJUnit(data, durations)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read durations from %s: %v", path, err)
+	}
+	return durations, nil
+}
+
+func durationsFromJSON(data []byte, durations map[string]time.Duration) error {
+	var seconds map[string]float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	for name, s := range seconds {
+		durations[name] = time.Duration(s * float64(time.Second))
+	}
+	return nil
+}
+
+func durationsFromJUnit(data []byte, durations map[string]time.Duration) error {
+	suites, err := readJUnitSuites(data)
+	if err != nil {
+		return err
+	}
+	for _, suite := range suites {
+		addSuiteDurations(suite, durations)
+	}
+	return nil
+}
+
+func addSuiteDurations(suite *JUnitTestSuite, durations map[string]time.Duration) {
+	for _, test := range suite.TestCases {
+		d := time.Duration(test.Duration * float64(time.Second))
+		if d > durations[test.Name] {
+			durations[test.Name] = d
+		}
+	}
+	for _, child := range suite.Children {
+		addSuiteDurations(child, durations)
+	}
+}
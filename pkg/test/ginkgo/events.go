@@ -0,0 +1,180 @@
+package ginkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/openshift/openshift-tests-private/pkg/monitor"
+)
+
+// The lifecycle events written by the suite runner when --output-format=ndjson.
+const (
+	EventSuiteStarted     = "suite-started"
+	EventTestStarted      = "test-started"
+	EventTestFinished     = "test-finished"
+	EventFlakeRetry       = "flake-retry"
+	EventMonitorCondition = "monitor-condition"
+)
+
+// RunnerEvent is a single line of the ndjson event stream.
+type RunnerEvent struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	// suite-started
+	Suite string `json:"suite,omitempty"`
+	Total int    `json:"total,omitempty"`
+
+	// test-started, test-finished and flake-retry
+	Test string `json:"test,omitempty"`
+	// test-finished
+	Result     TestResult `json:"result,omitempty"`
+	Duration   float64    `json:"duration,omitempty"`
+	OutputPath string     `json:"outputPath,omitempty"`
+
+	// monitor-condition
+	Level   string `json:"level,omitempty"`
+	Locator string `json:"locator,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+var unsafeFilenameCharacters = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// eventWriter writes runner events as newline delimited JSON. If outputDir is set
+// the output of each finished test is written to a file in that directory and the
+// path is included in the event. A nil eventWriter discards all events.
+type eventWriter struct {
+	lock      sync.Mutex
+	out       io.Writer
+	outputDir string
+	finished  int
+}
+
+func newEventWriter(out io.Writer, outputDir string) (*eventWriter, error) {
+	if len(outputDir) > 0 {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return nil, fmt.Errorf("could not create test output directory: %v", err)
+		}
+	}
+	return &eventWriter{out: out, outputDir: outputDir}, nil
+}
+
+func (w *eventWriter) write(event *RunnerEvent) {
+	if w == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.out.Write(append(data, '\n'))
+}
+
+func (w *eventWriter) SuiteStarted(suite string, total int) {
+	w.write(&RunnerEvent{Type: EventSuiteStarted, Suite: suite, Total: total})
+}
+
+func (w *eventWriter) TestStarted(test *testCase) {
+	w.write(&RunnerEvent{Type: EventTestStarted, Time: test.start.UTC(), Test: test.name})
+}
+
+func (w *eventWriter) FlakeRetry(test *testCase) {
+	w.write(&RunnerEvent{Type: EventFlakeRetry, Test: test.name})
+}
+
+func (w *eventWriter) TestFinished(test *testCase) {
+	if w == nil {
+		return
+	}
+	event := &RunnerEvent{
+		Type:     EventTestFinished,
+		Time:     test.end.UTC(),
+		Test:     test.name,
+		Duration: test.duration.Seconds(),
+	}
+	switch {
+	case test.success:
+		event.Result = TestResultPass
+	case test.skipped:
+		event.Result = TestResultSkip
+	default:
+		event.Result = TestResultFail
+	}
+	if len(w.outputDir) > 0 {
+		w.lock.Lock()
+		w.finished++
+		index := w.finished
+		w.lock.Unlock()
+		name := unsafeFilenameCharacters.ReplaceAllString(test.name, "_")
+		if len(name) > 100 {
+			name = name[:100]
+		}
+		path := filepath.Join(w.outputDir, fmt.Sprintf("%05d-%s.log", index, name))
+		if err := ioutil.WriteFile(path, test.out, 0640); err == nil {
+			event.OutputPath = path
+		}
+	}
+	w.write(event)
+}
+
+// StreamMonitor writes an event for every instantaneous condition recorded by m
+// until ctx is done.
+func (w *eventWriter) StreamMonitor(ctx context.Context, m monitor.Interface) {
+	if w == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		var last time.Time
+		done := false
+		for !done {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				done = true
+			}
+			events := m.Events(last, time.Time{})
+			if len(events) == 0 {
+				continue
+			}
+			for _, event := range events {
+				if !event.From.Equal(event.To) {
+					continue
+				}
+				w.write(&RunnerEvent{
+					Type:    EventMonitorCondition,
+					Time:    event.From,
+					Level:   eventLevelString(event.Level),
+					Locator: event.Locator,
+					Message: event.Message,
+				})
+			}
+			last = events[len(events)-1].From
+		}
+	}()
+}
+
+func eventLevelString(level monitor.EventLevel) string {
+	switch level {
+	case monitor.Warning:
+		return "Warning"
+	case monitor.Error:
+		return "Error"
+	default:
+		return "Info"
+	}
+}
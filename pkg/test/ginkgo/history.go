@@ -0,0 +1,282 @@
+package ginkgo
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	// register the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestResultFlake is recorded in the history for tests that failed and then passed
+// when retried.
+const TestResultFlake TestResult = "flake"
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	suite           TEXT NOT NULL,
+	started         TIMESTAMP NOT NULL,
+	duration        REAL NOT NULL,
+	cluster_version TEXT NOT NULL,
+	platform        TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	run_id   INTEGER NOT NULL REFERENCES runs(id),
+	name     TEXT NOT NULL,
+	subteam  TEXT NOT NULL,
+	result   TEXT NOT NULL,
+	duration REAL NOT NULL,
+	failure  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_name ON results(name);
+`
+
+// historyDB stores the results of suite runs in a local SQLite database.
+type historyDB struct {
+	db *sql.DB
+}
+
+func openHistoryDB(path string) (*historyDB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not initialize history database %s: %v", path, err)
+	}
+	return &historyDB{db: db}, nil
+}
+
+// openHistoryDBReadOnly opens an existing database to report from. Unlike opening
+// it to record, a missing database is an error instead of being created empty.
+func openHistoryDBReadOnly(path string) (*historyDB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("could not open history database: %v", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	return &historyDB{db: db}, nil
+}
+
+func (h *historyDB) Close() error {
+	return h.db.Close()
+}
+
+// historyRun describes a single suite run to record.
+type historyRun struct {
+	Suite    string
+	Started  time.Time
+	Duration time.Duration
+	Cluster  clusterInfo
+}
+
+// Record stores the results of the provided tests. Tests whose name is in flaky are
+// recorded as flakes instead of failures.
+func (h *historyDB) Record(run historyRun, tests []*testCase, flaky map[string]struct{}) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	result, err := tx.Exec(`INSERT INTO runs (suite, started, duration, cluster_version, platform) VALUES (?, ?, ?, ?, ?)`,
+		run.Suite, run.Started.UTC(), run.Duration.Seconds(), run.Cluster.Version, run.Cluster.Platform)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO results (run_id, name, subteam, result, duration, failure) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, test := range tests {
+		var r TestResult
+		var failure string
+		switch {
+		case test.success:
+			r = TestResultPass
+		case test.skipped:
+			r = TestResultSkip
+		case test.failed:
+			r = TestResultFail
+			if _, ok := flaky[test.name]; ok {
+				r = TestResultFlake
+			}
+			failure = lastLinesUntil(string(test.out), 5, "fail [")
+		default:
+			continue
+		}
+		if _, err := stmt.Exec(id, test.name, test.subteam, string(r), test.duration.Seconds(), failure); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// historyLock serializes writes to the database by runs against several clusters.
+var historyLock sync.Mutex
+
+// recordHistory stores the results of a suite run in the database at path, along
+// with the version and platform of the cluster if they are known.
+func recordHistory(path, suite string, started time.Time, duration time.Duration, cluster *clusterInfo, tests []*testCase, flaky map[string]struct{}) error {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+	run := historyRun{Suite: suite, Started: started, Duration: duration}
+	if cluster != nil {
+		run.Cluster = *cluster
+	}
+	db, err := openHistoryDB(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Record(run, tests, flaky)
+}
+
+// historyResult is the result of a test in a single recorded run.
+type historyResult struct {
+	Name     string
+	Sig      string
+	Subteam  string
+	Started  time.Time
+	Result   TestResult
+	Duration time.Duration
+}
+
+// Results returns every recorded result in the order the runs started.
+func (h *historyDB) Results() ([]*historyResult, error) {
+	rows, err := h.db.Query(`SELECT results.name, results.subteam, runs.started, results.result, results.duration FROM results JOIN runs ON runs.id = results.run_id ORDER BY runs.started, runs.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []*historyResult
+	for rows.Next() {
+		r := &historyResult{}
+		var result string
+		var seconds float64
+		if err := rows.Scan(&r.Name, &r.Subteam, &r.Started, &result, &seconds); err != nil {
+			return nil, err
+		}
+		r.Sig, _ = parseDescribe(r.Name)
+		r.Result = TestResult(result)
+		r.Duration = time.Duration(seconds * float64(time.Second))
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// group returns "sig-x.Subteam" for tests named "[sig-x] Subteam ...", as subteams
+// of different sigs may share a name.
+func (r *historyResult) group() string {
+	if len(r.Sig) == 0 {
+		return r.Subteam
+	}
+	return r.Sig + "." + r.Subteam
+}
+
+// HistoryStats summarizes the recorded results of a test or a subteam.
+type HistoryStats struct {
+	Name      string  `json:"name"`
+	Runs      int     `json:"runs"`
+	PassRate  float64 `json:"passRate"`
+	FlakeRate float64 `json:"flakeRate"`
+	FailRate  float64 `json:"failRate"`
+	Skipped   int     `json:"skipped"`
+
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+
+	// FirstFailedAt is the start of the run in which the current streak of failures
+	// began, or empty if the last run did not fail.
+	FirstFailedAt *time.Time `json:"firstFailedAt,omitempty"`
+}
+
+// summarizeHistory groups the results by key and computes their statistics. Skipped
+// results are counted but do not contribute to the rates or durations.
+func summarizeHistory(results []*historyResult, key func(*historyResult) string) []*HistoryStats {
+	type group struct {
+		stats       *HistoryStats
+		durations   []time.Duration
+		pass, flake int
+		fail        int
+		streak      *time.Time
+	}
+	groups := make(map[string]*group)
+	var names []string
+	for _, r := range results {
+		k := key(r)
+		g, ok := groups[k]
+		if !ok {
+			g = &group{stats: &HistoryStats{Name: k}}
+			groups[k] = g
+			names = append(names, k)
+		}
+		switch r.Result {
+		case TestResultSkip:
+			g.stats.Skipped++
+			continue
+		case TestResultPass:
+			g.pass++
+			g.streak = nil
+		case TestResultFlake:
+			g.flake++
+			g.streak = nil
+		case TestResultFail:
+			g.fail++
+			if g.streak == nil {
+				started := r.Started
+				g.streak = &started
+			}
+		}
+		g.stats.Runs++
+		g.durations = append(g.durations, r.Duration)
+	}
+
+	sort.Strings(names)
+	var stats []*HistoryStats
+	for _, name := range names {
+		g := groups[name]
+		if g.stats.Runs > 0 {
+			runs := float64(g.stats.Runs)
+			g.stats.PassRate = float64(g.pass) / runs
+			g.stats.FlakeRate = float64(g.flake) / runs
+			g.stats.FailRate = float64(g.fail) / runs
+		}
+		sort.Slice(g.durations, func(i, j int) bool { return g.durations[i] < g.durations[j] })
+		g.stats.P50 = percentile(g.durations, 0.5)
+		g.stats.P90 = percentile(g.durations, 0.9)
+		g.stats.P99 = percentile(g.durations, 0.99)
+		g.stats.FirstFailedAt = g.streak
+		stats = append(stats, g.stats)
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
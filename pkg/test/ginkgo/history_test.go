@@ -0,0 +1,115 @@
+package ginkgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistoryDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := openHistoryDB(filepath.Join(dir, "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := [][]*testCase{
+		{{name: "a", subteam: "NODE", success: true, duration: time.Minute}, {name: "b", subteam: "SDN", failed: true, out: []byte("fail [x.go:1]: boom")}},
+		{{name: "a", subteam: "NODE", failed: true, duration: 3 * time.Minute}, {name: "b", subteam: "SDN", skipped: true}},
+		{{name: "a", subteam: "NODE", failed: true, duration: 2 * time.Minute}, {name: "b", subteam: "SDN", failed: true}},
+	}
+	for i, tests := range runs {
+		flaky := map[string]struct{}{}
+		if i == 0 {
+			flaky["b"] = struct{}{}
+		}
+		run := historyRun{Suite: "all", Started: start.Add(time.Duration(i) * time.Hour), Cluster: clusterInfo{Version: "4.10.0", Platform: "AWS"}}
+		if err := db.Record(run, tests, flaky); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := db.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := summarizeHistory(results, func(r *historyResult) string { return r.Name })
+	if len(stats) != 2 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+	a, b := stats[0], stats[1]
+	if a.Runs != 3 || a.FailRate < 0.66 || a.FailRate > 0.67 || a.P50 != 2*time.Minute || a.P99 != 3*time.Minute {
+		t.Errorf("unexpected stats for a: %#v", a)
+	}
+	if a.FirstFailedAt == nil || !a.FirstFailedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected a to start failing in the second run, got %v", a.FirstFailedAt)
+	}
+	if b.Runs != 2 || b.Skipped != 1 || b.FlakeRate != 0.5 || b.FailRate != 0.5 {
+		t.Errorf("unexpected stats for b: %#v", b)
+	}
+	if b.FirstFailedAt == nil || !b.FirstFailedAt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("expected b to start failing in the third run, got %v", b.FirstFailedAt)
+	}
+
+	bySubteam := summarizeHistory(results, func(r *historyResult) string { return r.Subteam })
+	if len(bySubteam) != 2 || bySubteam[0].Name != "NODE" || bySubteam[1].Name != "SDN" {
+		t.Errorf("unexpected stats by subteam: %#v", bySubteam)
+	}
+}
+
+func TestHistoryDBReadOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	missing := filepath.Join(dir, "missing.db")
+	if _, err := openHistoryDBReadOnly(missing); err == nil {
+		t.Errorf("expected an error for a missing database")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("expected the missing database not to be created, got %v", err)
+	}
+
+	path := filepath.Join(dir, "history.db")
+	if err := recordHistory(path, "all", time.Now(), time.Minute, nil, []*testCase{
+		{name: "[sig-node] NODE a", subteam: "NODE", success: true},
+		{name: "[sig-mco] NODE b", subteam: "NODE", failed: true},
+		{name: "c", success: true},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	db, err := openHistoryDBReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	results, err := db.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Record(historyRun{Suite: "all", Started: time.Now()}, nil, nil); err == nil {
+		t.Errorf("expected a database opened to report from not to be written to")
+	}
+
+	// subteams of different sigs are grouped apart
+	stats := summarizeHistory(results, (*historyResult).group)
+	var groups []string
+	for _, s := range stats {
+		groups = append(groups, s.Name)
+	}
+	if want := []string{"", "sig-mco.NODE", "sig-node.NODE"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("unexpected groups %q, want %q", groups, want)
+	}
+}
@@ -6,13 +6,14 @@ import (
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
 // The below types are directly marshalled into XML. The types correspond to jUnit
-// XML schema, but do not contain all valid fields. For instance, the class name
-// field for test cases is omitted, as this concept does not directly apply to Go.
+// XML schema, but do not contain all valid fields. The class name of a test case is
+// derived from the "[sig-x] Subteam" prefix of the test name.
 // For XML specifications see http://help.catchsoftware.com/display/ET/JUnit+Format
 // or view the XSD included in this package as 'junit.xsd'
 
@@ -44,7 +45,7 @@ type JUnitTestSuite struct {
 	Duration float64 `xml:"time,attr"`
 
 	// Properties holds other properties of the test suite as a mapping of name to value
-	Properties []*TestSuiteProperty `xml:"properties,omitempty"`
+	Properties []*TestSuiteProperty `xml:"properties>property,omitempty"`
 
 	// TestCases are the test cases contained in the test suite
 	TestCases []*JUnitTestCase `xml:"testcase"`
@@ -85,6 +86,9 @@ type JUnitTestCase struct {
 
 	// SystemErr is output written to stderr during the execution of this test case
 	SystemErr string `xml:"system-err,omitempty"`
+
+	// Properties holds other properties of the test case, such as its owners
+	Properties []*TestSuiteProperty `xml:"properties>property,omitempty"`
 }
 
 // SkipMessage holds a message explaining why a test was skipped
@@ -115,54 +119,157 @@ const (
 	TestResultFail TestResult = "fail"
 )
 
-func writeJUnitReport(filePrefix, name string, tests []*testCase, dir string, duration time.Duration, errOut io.Writer, additionalResults ...*JUnitTestCase) error {
+// monitorTestName is the synthetic test case that carries the monitor timeline of a run.
+const monitorTestName = "Monitor cluster while tests execute"
+
+// monitorTimelineFile is written to the JUnit directory with the monitor timeline of
+// a run, which is only part of the report when the monitor detected errors.
+const monitorTimelineFile = "monitor-timeline.txt"
+
+// readJUnitSuites parses a JUnit report holding either a testsuites element or a
+// single testsuite.
+func readJUnitSuites(data []byte) ([]*JUnitTestSuite, error) {
+	var suites JUnitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		var suite JUnitTestSuite
+		if err := xml.Unmarshal(data, &suite); err != nil {
+			return nil, err
+		}
+		return []*JUnitTestSuite{&suite}, nil
+	}
+	return suites.Suites, nil
+}
+
+// newJUnitSuite builds the report of a run. If bySubteam is set the tests of each
+// subteam are written to a nested suite named like their classname, sig.subteam,
+// and the counts of the top level suite include the tests of all nested suites.
+func newJUnitSuite(name string, tests []*testCase, duration time.Duration, properties []*TestSuiteProperty, bySubteam bool, additionalResults ...*JUnitTestCase) *JUnitTestSuite {
 	s := &JUnitTestSuite{
-		Name:     name,
-		Duration: duration.Seconds(),
+		Name:       name,
+		Duration:   duration.Seconds(),
+		Properties: properties,
 	}
+	children := make(map[string]*JUnitTestSuite)
 	for _, test := range tests {
-		switch {
-		case test.skipped:
-			s.NumTests++
-			s.NumSkipped++
-			s.TestCases = append(s.TestCases, &JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.out),
-				Duration:  test.duration.Seconds(),
-				SkipMessage: &SkipMessage{
-					Message: lastLinesUntil(string(test.out), 100, "skip ["),
-				},
-			})
-		case test.failed:
-			s.NumTests++
-			s.NumFailed++
-			s.TestCases = append(s.TestCases, &JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.out),
-				Duration:  test.duration.Seconds(),
-				FailureOutput: &FailureOutput{
-					Output: lastLinesUntil(string(test.out), 100, "fail ["),
-				},
-			})
-		case test.success:
-			s.NumFailed++
-			s.TestCases = append(s.TestCases, &JUnitTestCase{
-				Name:     test.name,
-				Duration: test.duration.Seconds(),
-			})
+		target := s
+		if bySubteam && len(test.subteam) > 0 {
+			// subteams of different sigs may share a name
+			key := junitClassname(test)
+			child, ok := children[key]
+			if !ok {
+				child = &JUnitTestSuite{Name: key}
+				children[key] = child
+				s.Children = append(s.Children, child)
+			}
+			target = child
+		}
+		for _, result := range junitTestCases(test) {
+			target.addTestCase(result)
+			if target != s {
+				target.Duration += result.Duration
+				s.count(result)
+			}
 		}
 	}
+	sort.Slice(s.Children, func(i, j int) bool { return s.Children[i].Name < s.Children[j].Name })
 	for _, result := range additionalResults {
-		switch {
-		case result.SkipMessage != nil:
-			s.NumSkipped++
-		case result.FailureOutput != nil:
-			s.NumFailed++
+		s.addTestCase(result)
+	}
+	return s
+}
+
+// junitTestCases returns the results of a test that has finished running. Once both
+// halves of a pair of upgrade checks finished they are reported as one result.
+func junitTestCases(test *testCase) []*JUnitTestCase {
+	var results []*JUnitTestCase
+	switch {
+	case test.preCheck != nil && upgradeCheckFinished(test.preCheck):
+		return nil
+	case upgradeCheckFinished(test):
+		results = []*JUnitTestCase{junitUpgradeCheck(test)}
+	default:
+		results = junitTestResults(test)
+	}
+	for _, result := range results {
+		result.Properties = ownerProperties(test.owners)
+	}
+	return results
+}
+
+func junitTestResults(test *testCase) []*JUnitTestCase {
+	classname := junitClassname(test)
+	switch {
+	case test.skipped:
+		return []*JUnitTestCase{{
+			Name:      test.name,
+			Classname: classname,
+			SystemOut: string(test.out),
+			Duration:  test.duration.Seconds(),
+			SkipMessage: &SkipMessage{
+				Message: lastLinesUntil(string(test.out), 100, "skip ["),
+			},
+		}}
+	case test.failed:
+		failure := &FailureOutput{
+			Output: lastLinesUntil(string(test.out), 100, "fail ["),
+		}
+		if test.quarantine != nil {
+			failure.Message = test.quarantine.String()
+		}
+		if len(test.diagnostics) > 0 {
+			failure.Output += fmt.Sprintf("\n\nDiagnostics: %s", test.diagnostics)
+		}
+		results := []*JUnitTestCase{{
+			Name:          test.name,
+			Classname:     classname,
+			SystemOut:     string(test.out),
+			Duration:      test.duration.Seconds(),
+			FailureOutput: failure,
+		}}
+		// a failing and a passing result with the same name is reported as a flake
+		if test.quarantine != nil {
+			results = append(results, &JUnitTestCase{
+				Name:      test.name,
+				Classname: classname,
+				SystemOut: fmt.Sprintf("The failure of this test is reported as a flake, %s\n", test.quarantine),
+			})
 		}
-		s.NumTests++
-		s.TestCases = append(s.TestCases, result)
+		return results
+	case test.success:
+		return []*JUnitTestCase{{
+			Name:      test.name,
+			Classname: classname,
+			Duration:  test.duration.Seconds(),
+		}}
+	}
+	return nil
+}
+
+// junitClassname returns "sig-x.Subteam" for tests named "[sig-x] Subteam ...".
+func junitClassname(test *testCase) string {
+	if len(test.sig) == 0 {
+		return ""
+	}
+	return test.sig + "." + test.subteam
+}
+
+func (s *JUnitTestSuite) addTestCase(result *JUnitTestCase) {
+	s.count(result)
+	s.TestCases = append(s.TestCases, result)
+}
+
+func (s *JUnitTestSuite) count(result *JUnitTestCase) {
+	s.NumTests++
+	switch {
+	case result.SkipMessage != nil:
+		s.NumSkipped++
+	case result.FailureOutput != nil:
+		s.NumFailed++
 	}
-	out, err := xml.Marshal(s)
+}
+
+func writeJUnitReport(filePrefix string, suite *JUnitTestSuite, dir string, errOut io.Writer) error {
+	out, err := xml.Marshal(suite)
 	if err != nil {
 		return err
 	}
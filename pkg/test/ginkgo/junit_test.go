@@ -1,6 +1,11 @@
 package ginkgo
 
-import "testing"
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
 
 func Test_lastLines(t *testing.T) {
 	tests := []struct {
@@ -33,3 +38,52 @@ func Test_lastLines(t *testing.T) {
 		})
 	}
 }
+
+func Test_newJUnitSuite(t *testing.T) {
+	tests := []*testCase{
+		{name: "[sig-node] NODE a", sig: "sig-node", subteam: "NODE", success: true},
+		{name: "[sig-node] NODE b", sig: "sig-node", subteam: "NODE", failed: true, out: []byte("fail [b.go:1]: boom")},
+		{name: "[sig-network] SDN c", sig: "sig-network", subteam: "SDN", skipped: true},
+		{name: "[sig-network] SDN d", sig: "sig-network", subteam: "SDN", failed: true, quarantine: &QuarantineEntry{Owner: "x", Reason: "y", Expires: "2021-01-01"}},
+		{name: "[sig-network-edge] NODE f", sig: "sig-network-edge", subteam: "NODE", success: true},
+		{name: "e", success: true},
+	}
+	monitor := &JUnitTestCase{Name: monitorTestName, FailureOutput: &FailureOutput{Output: "1 error"}}
+
+	flat := newJUnitSuite("suite", tests, time.Minute, nil, false, monitor)
+	if flat.NumTests != 8 || flat.NumFailed != 3 || flat.NumSkipped != 1 || len(flat.Children) != 0 {
+		t.Errorf("unexpected counts: tests=%d failures=%d skipped=%d", flat.NumTests, flat.NumFailed, flat.NumSkipped)
+	}
+	if flat.TestCases[0].Classname != "sig-node.NODE" || flat.TestCases[7].Classname != "" {
+		t.Errorf("unexpected classnames: %q, %q", flat.TestCases[0].Classname, flat.TestCases[7].Classname)
+	}
+
+	nested := newJUnitSuite("suite", tests, time.Minute, nil, true, monitor)
+	if nested.NumTests != 8 || nested.NumFailed != 3 || nested.NumSkipped != 1 {
+		t.Errorf("unexpected counts: tests=%d failures=%d skipped=%d", nested.NumTests, nested.NumFailed, nested.NumSkipped)
+	}
+	var names []string
+	for _, child := range nested.Children {
+		names = append(names, child.Name)
+	}
+	if strings.Join(names, ",") != "sig-network-edge.NODE,sig-network.SDN,sig-node.NODE" {
+		t.Fatalf("unexpected children: %v", names)
+	}
+	if edge := nested.Children[0]; edge.NumTests != 1 {
+		t.Errorf("expected the subteam of another sig to have its own suite: tests=%d", edge.NumTests)
+	}
+	if sdn := nested.Children[1]; sdn.NumTests != 3 || sdn.NumFailed != 1 || sdn.NumSkipped != 1 {
+		t.Errorf("unexpected counts for SDN: tests=%d failures=%d skipped=%d", sdn.NumTests, sdn.NumFailed, sdn.NumSkipped)
+	}
+	if len(nested.TestCases) != 2 {
+		t.Errorf("expected only the tests without a subteam at the top level: %#v", nested.TestCases)
+	}
+
+	data, err := xml.Marshal(&JUnitTestSuite{Name: "suite", Properties: []*TestSuiteProperty{{Name: "platform", Value: "AWS"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<properties><property name="platform" value="AWS"></property></properties>`) {
+		t.Errorf("unexpected properties: %s", data)
+	}
+}
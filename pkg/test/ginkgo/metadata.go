@@ -0,0 +1,262 @@
+package ginkgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// importanceLevels are the recognized importance values of a test case, from
+// most to least important.
+var importanceLevels = []string{"Critical", "High", "Medium", "Low"}
+
+var (
+	authorPattern = regexp.MustCompile(`^Author:([A-Za-z0-9_.]+)$`)
+	caseIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	prefixPattern = regexp.MustCompile(`^[A-Za-z]+$`)
+
+	describePattern = regexp.MustCompile(`^\[(sig-[^\]]+)\]\s+([^\s\[]+)`)
+	labelPattern    = regexp.MustCompile(`\[([^\]]+)\]`)
+)
+
+// TestMetadata holds the structured fields encoded at the start of a test title,
+// for example "Longduration-NonPreRelease-Author:pmali-Medium-11600-kubelet will ...".
+type TestMetadata struct {
+	// Author is the id following "Author:" in the title.
+	Author string `json:"author,omitempty"`
+	// Importance is the highest importance of the cases covered by the test.
+	Importance string `json:"importance,omitempty"`
+	// CaseIDs are the test case IDs covered by the test, in title order.
+	CaseIDs []string `json:"caseIDs,omitempty"`
+	// Prefixes are the other markers in the title, such as Longduration,
+	// NonPreRelease or ConnectedOnly.
+	Prefixes []string `json:"prefixes,omitempty"`
+
+	// Warnings describes why the title does not follow the naming rule, or why a
+	// label of the test is invalid. Only titles that partially follow the rule are
+	// reported.
+	Warnings []string `json:"-"`
+}
+
+// HasPrefix returns true if the title carries the provided marker.
+func (m *TestMetadata) HasPrefix(prefix string) bool {
+	for _, p := range m.Prefixes {
+		if strings.EqualFold(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTestMetadata extracts the metadata from the text of a g.It block. Tokens
+// are separated by "-" and the metadata ends at the first token that is not an
+// author, importance, case ID or single word prefix once a case ID was seen.
+func parseTestMetadata(title string) TestMetadata {
+	var m TestMetadata
+	var pending string
+	var invalid []string
+
+	for _, token := range strings.Split(strings.TrimSpace(title), "-") {
+		if matches := authorPattern.FindStringSubmatch(token); matches != nil {
+			if len(m.Author) > 0 {
+				invalid = append(invalid, fmt.Sprintf("author is declared more than once (%s, %s)", m.Author, matches[1]))
+				continue
+			}
+			m.Author = matches[1]
+			continue
+		}
+		if importance, ok := normalizeImportance(token); ok {
+			if importance != token {
+				invalid = append(invalid, fmt.Sprintf("importance %q should be written as %q", token, importance))
+			}
+			pending = importance
+			continue
+		}
+		if caseIDPattern.MatchString(token) {
+			if len(pending) == 0 {
+				invalid = append(invalid, fmt.Sprintf("case ID %s has no importance", token))
+			} else if len(m.Importance) == 0 || importanceRank(pending) < importanceRank(m.Importance) {
+				m.Importance = pending
+			}
+			pending = ""
+			m.CaseIDs = append(m.CaseIDs, token)
+			continue
+		}
+		if len(m.CaseIDs) == 0 && prefixPattern.MatchString(token) {
+			m.Prefixes = append(m.Prefixes, token)
+			continue
+		}
+		break
+	}
+
+	// titles that carry none of the fields are not expected to follow the rule
+	if len(m.Author) == 0 && len(m.CaseIDs) == 0 && len(m.Importance) == 0 && len(pending) == 0 {
+		m.Prefixes = nil
+		return m
+	}
+	if len(pending) > 0 {
+		invalid = append(invalid, fmt.Sprintf("importance %s is not followed by a case ID", pending))
+	}
+	if len(m.Author) == 0 {
+		invalid = append(invalid, "missing Author:<id>")
+	}
+	if len(m.CaseIDs) == 0 {
+		invalid = append(invalid, "missing case ID")
+	}
+	m.Warnings = invalid
+	return m
+}
+
+// metadataFromName extracts the metadata from the full name of a test, which joins
+// the Describe text and the g.It title, as found in JUnit reports. The title is
+// expected to start at the first word that carries an author or a case ID.
+func metadataFromName(name string) TestMetadata {
+	for i := range name {
+		if i > 0 && name[i-1] != ' ' {
+			continue
+		}
+		if m := parseTestMetadata(name[i:]); len(m.Author) > 0 || len(m.CaseIDs) > 0 {
+			return m
+		}
+	}
+	return TestMetadata{}
+}
+
+// parseDescribe returns the sig and subteam from the leading "[sig-x] Subteam"
+// Describe text of a test name.
+func parseDescribe(name string) (sig, subteam string) {
+	matches := describePattern.FindStringSubmatch(name)
+	if matches == nil {
+		return "", ""
+	}
+	return matches[1], matches[2]
+}
+
+// testLabels returns the bracketed labels in a test name, such as "Serial" or
+// "Suite:openshift/conformance/parallel", excluding the sig.
+func testLabels(name string) []string {
+	var labels []string
+	for _, matches := range labelPattern.FindAllStringSubmatch(name, -1) {
+		if strings.HasPrefix(matches[1], "sig-") {
+			continue
+		}
+		labels = append(labels, matches[1])
+	}
+	return labels
+}
+
+// labelValue returns the value of the first "key:value" label with the provided key.
+func labelValue(labels []string, key string) (string, bool) {
+	for _, label := range labels {
+		if strings.HasPrefix(label, key+":") {
+			return strings.TrimPrefix(label, key+":"), true
+		}
+	}
+	return "", false
+}
+
+// labelValues returns the values of every "key:value" label with the provided key.
+func labelValues(labels []string, key string) []string {
+	var values []string
+	for _, label := range labels {
+		if strings.HasPrefix(label, key+":") {
+			values = append(values, strings.TrimPrefix(label, key+":"))
+		}
+	}
+	return values
+}
+
+// normalizeImportance returns the canonical spelling of an importance value.
+func normalizeImportance(value string) (string, bool) {
+	for _, level := range importanceLevels {
+		if strings.EqualFold(level, value) {
+			return level, true
+		}
+	}
+	return "", false
+}
+
+func importanceRank(importance string) int {
+	for i, level := range importanceLevels {
+		if level == importance {
+			return i
+		}
+	}
+	return len(importanceLevels)
+}
+
+// MetadataFilter selects tests by the metadata parsed from their titles. Empty
+// fields match every test.
+type MetadataFilter struct {
+	Importance      []string
+	Authors         []string
+	CaseIDs         []string
+	ExcludePrefixes []string
+}
+
+// Validate checks the filter values are well formed and normalizes the
+// importance values.
+func (f *MetadataFilter) Validate() error {
+	for i, value := range f.Importance {
+		importance, ok := normalizeImportance(value)
+		if !ok {
+			return fmt.Errorf("--importance must be one of %s, got %q", strings.Join(importanceLevels, ", "), value)
+		}
+		f.Importance[i] = importance
+	}
+	for _, id := range f.CaseIDs {
+		if !caseIDPattern.MatchString(id) {
+			return fmt.Errorf("--case-id must be numeric, got %q", id)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the provided metadata is selected by the filter.
+func (f *MetadataFilter) Matches(m *TestMetadata) bool {
+	if len(f.Importance) > 0 && !containsString(f.Importance, m.Importance) {
+		return false
+	}
+	if len(f.Authors) > 0 && !containsString(f.Authors, m.Author) {
+		return false
+	}
+	if len(f.CaseIDs) > 0 {
+		found := false
+		for _, id := range m.CaseIDs {
+			if containsString(f.CaseIDs, id) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, prefix := range f.ExcludePrefixes {
+		if m.HasPrefix(prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the tests selected by the filter.
+func (f *MetadataFilter) Filter(tests []*testCase) []*testCase {
+	matches := make([]*testCase, 0, len(tests))
+	for _, test := range tests {
+		if !f.Matches(&test.metadata) {
+			continue
+		}
+		matches = append(matches, test)
+	}
+	return matches
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package ginkgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseTestMetadata(t *testing.T) {
+	tests := []struct {
+		title    string
+		want     TestMetadata
+		warnings int
+	}{
+		{
+			title: "Author:pmali-High-12893-Init containers with restart policy Always",
+			want:  TestMetadata{Author: "pmali", Importance: "High", CaseIDs: []string{"12893"}},
+		},
+		{
+			title: "Longduration-NonPreRelease-Author:pmali-Medium-11600-kubelet will evict pod immediately [Disruptive]",
+			want:  TestMetadata{Author: "pmali", Importance: "Medium", CaseIDs: []string{"11600"}, Prefixes: []string{"Longduration", "NonPreRelease"}},
+		},
+		{
+			title: "NonPreRelease-Author:yingwang-Medium-Longduration-42253-Pod with sriov interface",
+			want:  TestMetadata{Author: "yingwang", Importance: "Medium", CaseIDs: []string{"42253"}, Prefixes: []string{"NonPreRelease", "Longduration"}},
+		},
+		{
+			title:    "ConnectedOnly-High-37826-Low-23170-Critical-20979-use an PullSecret for the private Catalog Source image [Serial]",
+			want:     TestMetadata{Importance: "Critical", CaseIDs: []string{"37826", "23170", "20979"}, Prefixes: []string{"ConnectedOnly"}},
+			warnings: 1,
+		},
+		{
+			title:    "Author:jdoe-high-1234-lower case importance",
+			want:     TestMetadata{Author: "jdoe", Importance: "High", CaseIDs: []string{"1234"}},
+			warnings: 1,
+		},
+		{
+			title:    "Author:jdoe-1234-no importance",
+			want:     TestMetadata{Author: "jdoe", CaseIDs: []string{"1234"}},
+			warnings: 1,
+		},
+		{
+			title: "should do something in the cluster [Serial]",
+			want:  TestMetadata{},
+		},
+		{
+			title: "Pods should-be-created",
+			want:  TestMetadata{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := parseTestMetadata(tt.title)
+			if len(got.Warnings) != tt.warnings {
+				t.Errorf("parseTestMetadata() warnings = %v, want %d", got.Warnings, tt.warnings)
+			}
+			got.Warnings = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTestMetadata() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataFilter_Matches(t *testing.T) {
+	m := parseTestMetadata("Longduration-Author:pmali-High-12893-Medium-12894-Init containers")
+	tests := []struct {
+		name   string
+		filter MetadataFilter
+		want   bool
+	}{
+		{name: "empty", want: true},
+		{name: "importance", filter: MetadataFilter{Importance: []string{"Critical", "High"}}, want: true},
+		{name: "other importance", filter: MetadataFilter{Importance: []string{"Medium"}}, want: false},
+		{name: "author", filter: MetadataFilter{Authors: []string{"pmali"}}, want: true},
+		{name: "other author", filter: MetadataFilter{Authors: []string{"minmli"}}, want: false},
+		{name: "second case", filter: MetadataFilter{CaseIDs: []string{"12894"}}, want: true},
+		{name: "other case", filter: MetadataFilter{CaseIDs: []string{"1"}}, want: false},
+		{name: "excluded prefix", filter: MetadataFilter{ExcludePrefixes: []string{"longduration"}}, want: false},
+		{name: "combined", filter: MetadataFilter{Authors: []string{"pmali"}, ExcludePrefixes: []string{"NonPreRelease"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(&m); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseDescribe(t *testing.T) {
+	tests := []struct {
+		name        string
+		wantSig     string
+		wantSubteam string
+		wantLabels  []string
+	}{
+		{
+			name:        "[sig-node] NODE Probe feature Author:minmli-High-41579-Liveness probe failures [Serial] [Suite:openshift/conformance/serial]",
+			wantSig:     "sig-node",
+			wantSubteam: "NODE",
+			wantLabels:  []string{"Serial", "Suite:openshift/conformance/serial"},
+		},
+		{
+			name:        "[sig-networking] SDN sriov Author:zzhao-Medium-Longduration-25321-Check intel dpdk works well [Disruptive]",
+			wantSig:     "sig-networking",
+			wantSubteam: "SDN",
+			wantLabels:  []string{"Disruptive"},
+		},
+		{
+			name: "Building from a template should create a build",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, subteam := parseDescribe(tt.name)
+			if sig != tt.wantSig || subteam != tt.wantSubteam {
+				t.Errorf("parseDescribe() = %q, %q, want %q, %q", sig, subteam, tt.wantSig, tt.wantSubteam)
+			}
+			if labels := testLabels(tt.name); !reflect.DeepEqual(labels, tt.wantLabels) {
+				t.Errorf("testLabels() = %v, want %v", labels, tt.wantLabels)
+			}
+		})
+	}
+}
+
+func Test_metadataFromName(t *testing.T) {
+	tests := []struct {
+		name string
+		want TestMetadata
+	}{
+		{
+			name: "[sig-node] NODE Probe feature Author:minmli-High-41579-Liveness probe failures [Serial]",
+			want: TestMetadata{Author: "minmli", Importance: "High", CaseIDs: []string{"41579"}},
+		},
+		{
+			name: "[sig-node] NODE pre-upgrade checks Longduration-NonPreRelease-Author:pmali-Medium-11600-kubelet will evict pod",
+			want: TestMetadata{Author: "pmali", Importance: "Medium", CaseIDs: []string{"11600"}, Prefixes: []string{"Longduration", "NonPreRelease"}},
+		},
+		{
+			name: "[sig-node] NODE Building from a template should create a build",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metadataFromName(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metadataFromName() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
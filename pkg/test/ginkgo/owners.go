@@ -0,0 +1,145 @@
+package ginkgo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ownersFile is the name of the files listing the people who own the tests in a
+// directory and its subdirectories.
+const ownersFile = "OWNERS"
+
+// testsDir is the directory of the extended tests in the location of a test, which
+// the OWNERS files are resolved relative to.
+const testsDir = "/test/extended/"
+
+// Owners are the approvers and reviewers of the nearest OWNERS file of a test. For
+// example:
+//
+//	approvers:
+//	- pmali
+//	reviewers:
+//	- minmli
+type Owners struct {
+	// Path is the OWNERS file relative to test/extended.
+	Path      string   `json:"path"`
+	Approvers []string `json:"approvers,omitempty"`
+	Reviewers []string `json:"reviewers,omitempty"`
+}
+
+// Includes returns true if the GitHub id is an approver or a reviewer. GitHub ids
+// are not case sensitive.
+func (o *Owners) Includes(id string) bool {
+	for _, owner := range append(append([]string{}, o.Approvers...), o.Reviewers...) {
+		if strings.EqualFold(owner, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnersIndex resolves the location of a test to the nearest OWNERS file in its
+// directory or a parent directory of it below test/extended.
+type OwnersIndex struct {
+	fsys fs.FS
+
+	lock  sync.Mutex
+	cache map[string]*Owners
+}
+
+// NewOwnersIndex reads OWNERS files from fsys, which is rooted at test/extended.
+func NewOwnersIndex(fsys fs.FS) *OwnersIndex {
+	return &OwnersIndex{fsys: fsys, cache: make(map[string]*Owners)}
+}
+
+// Lookup returns the owners of the test at fileName, or nil if the file is not in
+// test/extended or no OWNERS file covers it. Files vendored from other projects
+// have no owners.
+func (idx *OwnersIndex) Lookup(fileName string) (*Owners, error) {
+	i := strings.LastIndex(fileName, testsDir)
+	if i == -1 || strings.Contains(fileName[:i+1], "/vendor/") {
+		return nil, nil
+	}
+	return idx.lookupDir(path.Dir(fileName[i+len(testsDir):]))
+}
+
+func (idx *OwnersIndex) lookupDir(dir string) (*Owners, error) {
+	idx.lock.Lock()
+	owners, ok := idx.cache[dir]
+	idx.lock.Unlock()
+	if ok {
+		return owners, nil
+	}
+
+	file := path.Join(dir, ownersFile)
+	data, err := fs.ReadFile(idx.fsys, file)
+	switch {
+	case err == nil:
+		owners = &Owners{}
+		if err := yaml.Unmarshal(data, owners); err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", file, err)
+		}
+		owners.Path = file
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	case dir != ".":
+		owners, err = idx.lookupDir(path.Dir(dir))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	idx.cache[dir] = owners
+	return owners, nil
+}
+
+// setOwners resolves the owners of each test, and warns about tests whose title
+// names an author that is not an approver or reviewer of the test.
+func setOwners(tests []*testCase, idx *OwnersIndex) error {
+	for _, test := range tests {
+		owners, err := idx.Lookup(test.location.FileName)
+		if err != nil {
+			return err
+		}
+		test.owners = owners
+		if owners == nil || len(test.metadata.Author) == 0 || owners.Includes(test.metadata.Author) {
+			continue
+		}
+		test.metadata.Warnings = append(test.metadata.Warnings, fmt.Sprintf("author %s is not an approver or reviewer in test/extended/%s", test.metadata.Author, owners.Path))
+	}
+	return nil
+}
+
+// filterByOwner returns the tests the GitHub id is an approver or reviewer of.
+func filterByOwner(tests []*testCase, id string) []*testCase {
+	matches := make([]*testCase, 0, len(tests))
+	for _, test := range tests {
+		if test.owners != nil && test.owners.Includes(id) {
+			matches = append(matches, test)
+		}
+	}
+	return matches
+}
+
+// ownerProperties returns the owners of a test as properties of its JUnit test case.
+func ownerProperties(owners *Owners) []*TestSuiteProperty {
+	if owners == nil {
+		return nil
+	}
+	var properties []*TestSuiteProperty
+	if len(owners.Approvers) > 0 {
+		properties = append(properties, &TestSuiteProperty{Name: "approvers", Value: strings.Join(owners.Approvers, ",")})
+	}
+	if len(owners.Reviewers) > 0 {
+		properties = append(properties, &TestSuiteProperty{Name: "reviewers", Value: strings.Join(owners.Reviewers, ",")})
+	}
+	return properties
+}
@@ -0,0 +1,77 @@
+package ginkgo
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/onsi/ginkgo/types"
+)
+
+func TestOwnersIndex(t *testing.T) {
+	idx := NewOwnersIndex(fstest.MapFS{
+		"OWNERS":                {Data: []byte("approvers:\n- yapei\n")},
+		"node/OWNERS":           {Data: []byte("reviewers:\n- sunilcio\n- rhpmali\napprovers:\n- minmli\n")},
+		"operators/olm/.keep":   {},
+		"operators/OWNERS":      {Data: []byte("approvers:\n- kuiwang02\n")},
+		"networking/OWNERS":     {Data: []byte("approvers: [\n")},
+		"networking/sdn/sdn.go": {},
+	})
+	node := &Owners{Path: "node/OWNERS", Approvers: []string{"minmli"}, Reviewers: []string{"sunilcio", "rhpmali"}}
+	tests := []struct {
+		fileName string
+		want     *Owners
+		wantErr  bool
+	}{
+		{fileName: "/go/src/github.com/openshift/openshift-tests-private/test/extended/node/node.go", want: node},
+		{fileName: "/root/module/test/extended/operators/olm/olm.go", want: &Owners{Path: "operators/OWNERS", Approvers: []string{"kuiwang02"}}},
+		{fileName: "/root/module/test/extended/ota/cvo/cvo.go", want: &Owners{Path: "OWNERS", Approvers: []string{"yapei"}}},
+		{fileName: "/root/module/vendor/k8s.io/kubernetes/test/e2e/apps/disruption.go"},
+		{fileName: "/root/module/vendor/github.com/openshift/origin/test/extended/builds/build.go"},
+		{fileName: "/root/module/test/extended/networking/sdn/sdn.go", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := idx.Lookup(test.fileName)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Lookup(%s) unexpected error: %v", test.fileName, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Lookup(%s) = %#v, want %#v", test.fileName, got, test.want)
+		}
+	}
+
+	nodeTest := func(name, title string) *testCase {
+		return &testCase{
+			name:     name,
+			location: types.CodeLocation{FileName: "/root/module/test/extended/node/node.go"},
+			metadata: parseTestMetadata(title),
+		}
+	}
+	owned := nodeTest("[sig-node] NODE Author:minmli-High-41579-Liveness probe", "Author:minmli-High-41579-Liveness probe")
+	reviewed := nodeTest("[sig-node] NODE Author:RHPMali-High-11600-kubelet evicts pods", "Author:RHPMali-High-11600-kubelet evicts pods")
+	stranger := nodeTest("[sig-node] NODE Author:zzhao-High-25321-egress", "Author:zzhao-High-25321-egress")
+	vendored := &testCase{name: "[sig-apps] DisruptionController", location: types.CodeLocation{FileName: "/root/module/vendor/k8s.io/kubernetes/test/e2e/apps/disruption.go"}}
+	all := []*testCase{owned, reviewed, stranger, vendored}
+	if err := setOwners(all, idx); err != nil {
+		t.Fatal(err)
+	}
+	if len(owned.metadata.Warnings) != 0 || len(reviewed.metadata.Warnings) != 0 || vendored.owners != nil {
+		t.Errorf("unexpected owners: %#v %#v %#v", owned.metadata.Warnings, reviewed.metadata.Warnings, vendored.owners)
+	}
+	if want := []string{"author zzhao is not an approver or reviewer in test/extended/node/OWNERS"}; !reflect.DeepEqual(stranger.metadata.Warnings, want) {
+		t.Errorf("unexpected warnings %v", stranger.metadata.Warnings)
+	}
+	if got := filterByOwner(all, "sunilcio"); !reflect.DeepEqual(got, []*testCase{owned, reviewed, stranger}) {
+		t.Errorf("unexpected tests owned by sunilcio: %v", got)
+	}
+	if got := filterByOwner(all, "zzhao"); len(got) != 0 {
+		t.Errorf("unexpected tests owned by zzhao: %v", got)
+	}
+
+	owned.success = true
+	want := []*TestSuiteProperty{{Name: "approvers", Value: "minmli"}, {Name: "reviewers", Value: "sunilcio,rhpmali"}}
+	if got := junitTestCases(owned)[0].Properties; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected JUnit properties %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The values of --preflight.
+const (
+	PreflightReport = "report"
+	PreflightAbort  = "abort"
+)
+
+// preflightTestName is the synthetic test case that reports the health of the
+// cluster before the suite started.
+const preflightTestName = "Cluster is healthy before tests execute"
+
+// preflightTimeout bounds how long the check waits for the monitor to observe the
+// cluster.
+const preflightTimeout = 2 * time.Minute
+
+// healthChecker is implemented by monitors that can describe the health of the
+// cluster.
+type healthChecker interface {
+	CheckHealth(ctx context.Context) []string
+}
+
+// runPreflight checks the health of the cluster and returns the result as a
+// synthetic test case along with the problems found.
+func runPreflight(ctx context.Context, checker healthChecker) (*JUnitTestCase, []string) {
+	start := time.Now()
+	checkCtx, cancelFn := context.WithTimeout(ctx, preflightTimeout)
+	defer cancelFn()
+	problems := checker.CheckHealth(checkCtx)
+	result := &JUnitTestCase{
+		Name:     preflightTestName,
+		Duration: time.Now().Sub(start).Seconds(),
+	}
+	if len(problems) > 0 {
+		result.FailureOutput = &FailureOutput{
+			Message: fmt.Sprintf("%d problems were found before the suite started", len(problems)),
+			Output:  strings.Join(problems, "\n"),
+		}
+	}
+	return result, problems
+}
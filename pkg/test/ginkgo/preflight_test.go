@@ -0,0 +1,24 @@
+package ginkgo
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeHealthChecker []string
+
+func (c fakeHealthChecker) CheckHealth(ctx context.Context) []string {
+	return c
+}
+
+func Test_runPreflight(t *testing.T) {
+	result, problems := runPreflight(context.Background(), fakeHealthChecker(nil))
+	if len(problems) != 0 || result.FailureOutput != nil || result.Name != preflightTestName {
+		t.Errorf("expected a passing preflight test, got %#v", result)
+	}
+
+	result, problems = runPreflight(context.Background(), fakeHealthChecker{"node/a is not Ready", "machineconfigpool/worker is updating"})
+	if len(problems) != 2 || result.FailureOutput == nil || result.FailureOutput.Output != "node/a is not Ready\nmachineconfigpool/worker is updating" {
+		t.Errorf("expected a failing preflight test, got %#v", result)
+	}
+}
@@ -0,0 +1,98 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// QuarantineFile lists known-flaky tests whose failures are reported as flakes and
+// do not fail the suite. For example:
+//
+//	tests:
+//	- caseID: "41579"
+//	  owner: minmli
+//	  reason: https://bugzilla.redhat.com/show_bug.cgi?id=2000000
+//	  expires: "2022-03-01"
+type QuarantineFile struct {
+	Tests []*QuarantineEntry `json:"tests"`
+}
+
+// QuarantineEntry identifies a test by its full name or by a case ID in its title.
+type QuarantineEntry struct {
+	Name    string `json:"name,omitempty"`
+	CaseID  string `json:"caseID,omitempty"`
+	Owner   string `json:"owner"`
+	Reason  string `json:"reason"`
+	Expires string `json:"expires"`
+}
+
+func (e *QuarantineEntry) String() string {
+	return fmt.Sprintf("quarantined by %s until %s: %s", e.Owner, e.Expires, e.Reason)
+}
+
+// quarantine matches tests against the unexpired entries of a quarantine file.
+type quarantine struct {
+	names   map[string]*QuarantineEntry
+	caseIDs map[string]*QuarantineEntry
+}
+
+// loadQuarantine reads the quarantine file at path. Entries that expired before now
+// are reported to warnOut and ignored, so that their failures fail the suite again.
+func loadQuarantine(path string, now time.Time, warnOut io.Writer) (*quarantine, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file QuarantineFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("could not read quarantine file %s: %v", path, err)
+	}
+	q := &quarantine{
+		names:   make(map[string]*QuarantineEntry),
+		caseIDs: make(map[string]*QuarantineEntry),
+	}
+	for i, entry := range file.Tests {
+		if (len(entry.Name) == 0) == (len(entry.CaseID) == 0) {
+			return nil, fmt.Errorf("quarantine entry %d must set exactly one of name or caseID", i)
+		}
+		if len(entry.Owner) == 0 || len(entry.Reason) == 0 {
+			return nil, fmt.Errorf("quarantine entry %d must set an owner and a reason", i)
+		}
+		expires, err := time.Parse("2006-01-02", entry.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("quarantine entry %d must set expires to a date like 2006-01-02: %v", i, err)
+		}
+		id := entry.Name
+		if len(id) == 0 {
+			id = "case " + entry.CaseID
+		}
+		// an entry applies until the end of its expiry day
+		if !now.Before(expires.AddDate(0, 0, 1)) {
+			fmt.Fprintf(warnOut, "warning: quarantine of %s owned by %s expired on %s, its failures are no longer ignored\n", id, entry.Owner, entry.Expires)
+			continue
+		}
+		if len(entry.Name) > 0 {
+			q.names[entry.Name] = entry
+		} else {
+			q.caseIDs[entry.CaseID] = entry
+		}
+	}
+	return q, nil
+}
+
+// Lookup returns the entry that quarantines test, or nil.
+func (q *quarantine) Lookup(test *testCase) *QuarantineEntry {
+	if entry, ok := q.names[test.name]; ok {
+		return entry
+	}
+	for _, id := range test.metadata.CaseIDs {
+		if entry, ok := q.caseIDs[id]; ok {
+			return entry
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package ginkgo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadQuarantine(t *testing.T) {
+	f, err := ioutil.TempFile("", "quarantine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(`
+tests:
+- name: "[sig-node] NODE a test"
+  owner: pmali
+  reason: bug 1
+  expires: "2022-01-31"
+- caseID: "41579"
+  owner: minmli
+  reason: bug 2
+  expires: "2022-03-01"
+- caseID: "12893"
+  owner: pmali
+  reason: bug 3
+  expires: "2021-12-31"
+`)
+	f.Close()
+
+	warnings := &bytes.Buffer{}
+	q, err := loadQuarantine(f.Name(), time.Date(2022, 1, 31, 12, 0, 0, 0, time.UTC), warnings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(warnings.String(), "case 12893") || strings.Count(warnings.String(), "\n") != 1 {
+		t.Errorf("expected only the expired entry to be reported, got %q", warnings.String())
+	}
+
+	tests := []struct {
+		test  *testCase
+		owner string
+	}{
+		{test: &testCase{name: "[sig-node] NODE a test"}, owner: "pmali"},
+		{test: &testCase{name: "other", metadata: TestMetadata{CaseIDs: []string{"1", "41579"}}}, owner: "minmli"},
+		{test: &testCase{name: "expired", metadata: TestMetadata{CaseIDs: []string{"12893"}}}},
+		{test: &testCase{name: "unknown"}},
+	}
+	for _, tt := range tests {
+		entry := q.Lookup(tt.test)
+		switch {
+		case len(tt.owner) == 0 && entry != nil:
+			t.Errorf("%s: expected no quarantine, got %v", tt.test.name, entry)
+		case len(tt.owner) > 0 && (entry == nil || entry.Owner != tt.owner):
+			t.Errorf("%s: expected quarantine owned by %s, got %v", tt.test.name, tt.owner, entry)
+		}
+	}
+}
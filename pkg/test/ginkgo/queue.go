@@ -3,19 +3,24 @@ package ginkgo
 import (
 	"container/ring"
 	"context"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 // parallelByFileTestQueue runs tests in parallel unless they have
-// the `[Serial]` tag on their name or if another test with the
-// testExclusion field is currently running. Serial tests are
-// defered until all other tests are completed.
+// the `[Serial]` tag on their name or if another test holding one
+// of their exclusions is currently running. Serial tests are
+// defered until all other tests are completed. Tests are started
+// in the order given, or longest first when durations are known.
+// No tests are started after the deadline, if one is set.
 type parallelByFileTestQueue struct {
-	cond   *sync.Cond
-	lock   sync.Mutex
-	queue  *ring.Ring
-	active map[string]struct{}
+	cond     *sync.Cond
+	lock     sync.Mutex
+	queue    *ring.Ring
+	active   map[string]struct{}
+	deadline time.Time
 }
 
 type nopLock struct{}
@@ -25,7 +30,13 @@ func (nopLock) Unlock() {}
 
 type TestFunc func(ctx context.Context, test *testCase)
 
-func newParallelTestQueue(tests []*testCase) *parallelByFileTestQueue {
+// newParallelTestQueue creates a queue for tests. If durations is not nil the tests
+// are started longest first, using the estimate for a test's labels when it has no
+// recorded duration.
+func newParallelTestQueue(tests []*testCase, durations map[string]time.Duration) *parallelByFileTestQueue {
+	if durations != nil {
+		tests = sortTestsByDuration(tests, durations)
+	}
 	r := ring.New(len(tests))
 	for _, test := range tests {
 		r.Value = test
@@ -44,34 +55,55 @@ func (q *parallelByFileTestQueue) pop() (*testCase, bool) {
 	defer q.lock.Unlock()
 	r := q.queue
 	l := r.Len()
-	if l == 0 {
+	if l == 0 || q.expired() {
 		q.cond.Broadcast()
 		return nil, true
 	}
 	for i := 0; i < l; i++ {
 		t := r.Value.(*testCase)
-		if _, ok := q.active[t.testExclusion]; ok {
+		if q.excluded(t) {
 			r = r.Next()
 			continue
 		}
-		if len(t.testExclusion) > 0 {
-			q.active[t.testExclusion] = struct{}{}
+		for _, key := range t.exclusions() {
+			q.active[key] = struct{}{}
 		}
-		if l == 1 {
+		// keep the queue at the first remaining test so the order is preserved
+		switch {
+		case l == 1:
 			q.queue = nil
-		} else {
-			q.queue = r.Prev()
-			q.queue.Unlink(1)
+		case r == q.queue:
+			q.queue = r.Next()
+			r.Prev().Unlink(1)
+		default:
+			r.Prev().Unlink(1)
 		}
 		return t, true
 	}
 	return nil, false
 }
 
+// excluded returns true if a running test holds one of the exclusions of t.
+func (q *parallelByFileTestQueue) excluded(t *testCase) bool {
+	for _, key := range t.exclusions() {
+		if _, ok := q.active[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expired returns true once the deadline of the queue has passed.
+func (q *parallelByFileTestQueue) expired() bool {
+	return !q.deadline.IsZero() && !time.Now().Before(q.deadline)
+}
+
 func (q *parallelByFileTestQueue) done(t *testCase) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	delete(q.active, t.testExclusion)
+	for _, key := range t.exclusions() {
+		delete(q.active, key)
+	}
 	q.cond.Broadcast()
 }
 
@@ -131,10 +163,48 @@ func (q *parallelByFileTestQueue) Execute(parentCtx context.Context, parallelism
 			return
 		default:
 		}
+		if q.expired() {
+			return
+		}
 		fn(parentCtx, test)
 	}
 }
 
+// labelDurationEstimates are used to order tests that have no recorded duration.
+var labelDurationEstimates = []struct {
+	matches  func(*testCase) bool
+	duration time.Duration
+}{
+	{matches: func(t *testCase) bool { return t.metadata.HasPrefix("Longduration") }, duration: 60 * time.Minute},
+	{matches: func(t *testCase) bool { return strings.Contains(t.name, "[Slow]") }, duration: 15 * time.Minute},
+}
+
+// defaultDurationEstimate is used for tests with no recorded duration or known label.
+const defaultDurationEstimate = 2 * time.Minute
+
+func estimateDuration(test *testCase, durations map[string]time.Duration) time.Duration {
+	if d, ok := durations[test.name]; ok {
+		return d
+	}
+	for _, estimate := range labelDurationEstimates {
+		if estimate.matches(test) {
+			return estimate.duration
+		}
+	}
+	return defaultDurationEstimate
+}
+
+// sortTestsByDuration returns a copy of tests ordered longest first. Tests with the
+// same duration keep their relative order.
+func sortTestsByDuration(tests []*testCase, durations map[string]time.Duration) []*testCase {
+	copied := make([]*testCase, len(tests))
+	copy(copied, tests)
+	sort.SliceStable(copied, func(i, j int) bool {
+		return estimateDuration(copied[i], durations) > estimateDuration(copied[j], durations)
+	})
+	return copied
+}
+
 func setTestExclusion(tests []*testCase, fn func(suitePath string, t *testCase) bool) {
 	for _, test := range tests {
 		summary := test.spec.Summary("")
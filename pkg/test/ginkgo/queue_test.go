@@ -0,0 +1,115 @@
+package ginkgo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParallelTestQueue_Durations(t *testing.T) {
+	tests := []*testCase{
+		{name: "a"},
+		{name: "b [Serial]"},
+		{name: "c [Slow]"},
+		{name: "d", metadata: TestMetadata{Prefixes: []string{"Longduration"}}},
+		{name: "e"},
+		{name: "f"},
+	}
+	durations := map[string]time.Duration{
+		"a":          time.Second,
+		"b [Serial]": time.Hour,
+		"f":          30 * time.Minute,
+	}
+
+	var order []string
+	newParallelTestQueue(tests, durations).Execute(context.Background(), 1, func(ctx context.Context, test *testCase) {
+		order = append(order, test.name)
+	})
+	if want := []string{"d", "f", "c [Slow]", "e", "a", "b [Serial]"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("unexpected order %v, want %v", order, want)
+	}
+
+	order = nil
+	newParallelTestQueue(tests, nil).Execute(context.Background(), 1, func(ctx context.Context, test *testCase) {
+		order = append(order, test.name)
+	})
+	if want := []string{"a", "c [Slow]", "d", "e", "f", "b [Serial]"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("unexpected order %v, want %v", order, want)
+	}
+}
+
+func TestParallelTestQueue_Exclusion(t *testing.T) {
+	tests := []*testCase{
+		{name: "a", testExclusion: "file"},
+		{name: "b", testExclusion: "file"},
+		{name: "c"},
+	}
+	q := newParallelTestQueue(tests, map[string]time.Duration{"a": time.Hour, "b": time.Minute})
+	first, _ := q.pop()
+	second, _ := q.pop()
+	if first.name != "a" || second.name != "c" {
+		t.Fatalf("expected the excluded test to be passed over, got %q and %q", first.name, second.name)
+	}
+	if test, ok := q.pop(); ok || test != nil {
+		t.Fatalf("expected no test to be available while a is running, got %v", test)
+	}
+	q.done(first)
+	if test, _ := q.pop(); test == nil || test.name != "b" {
+		t.Fatalf("expected b after a completed, got %v", test)
+	}
+}
+
+func TestParallelTestQueue_MultipleExclusions(t *testing.T) {
+	tests := []*testCase{
+		{name: "a", exclusive: []string{"Exclusive:mcp-worker", "Exclusive:ingress-default"}},
+		{name: "b", exclusive: []string{"Exclusive:ingress-default"}},
+		{name: "c", exclusive: []string{"Exclusive:mcp-worker"}},
+		{name: "d"},
+	}
+	q := newParallelTestQueue(tests, nil)
+	first, _ := q.pop()
+	second, _ := q.pop()
+	if first.name != "a" || second.name != "d" {
+		t.Fatalf("expected the tests sharing an exclusion with a to be passed over, got %q and %q", first.name, second.name)
+	}
+	if test, ok := q.pop(); ok || test != nil {
+		t.Fatalf("expected no test to be available while a is running, got %v", test)
+	}
+	q.done(first)
+	third, _ := q.pop()
+	fourth, _ := q.pop()
+	if third == nil || fourth == nil || third.name != "b" || fourth.name != "c" {
+		t.Fatalf("expected b and c to run together after a completed, got %v and %v", third, fourth)
+	}
+}
+
+func TestParallelTestQueue_Deadline(t *testing.T) {
+	tests := []*testCase{
+		{name: "a"},
+		{name: "b"},
+		{name: "c [Serial]"},
+	}
+	q := newParallelTestQueue(tests, nil)
+	q.deadline = time.Now().Add(time.Hour)
+	first, _ := q.pop()
+	if first == nil || first.name != "a" {
+		t.Fatalf("expected a before the deadline, got %v", first)
+	}
+	q.done(first)
+
+	q.deadline = time.Now().Add(-time.Second)
+	if test, ok := q.pop(); !ok || test != nil {
+		t.Fatalf("expected the queue to be finished after the deadline, got %v", test)
+	}
+
+	var started []string
+	q = newParallelTestQueue(tests, nil)
+	q.deadline = time.Now().Add(-time.Second)
+	q.Execute(context.Background(), 2, func(ctx context.Context, test *testCase) {
+		started = append(started, test.name)
+	})
+	if len(started) != 0 {
+		t.Errorf("expected no tests to start after the deadline, got %v", started)
+	}
+}
@@ -0,0 +1,162 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// reportPortalClient creates launches, items and logs through the ReportPortal v1 API.
+type reportPortalClient struct {
+	// base is the API endpoint including the project, like https://host/api/v1/ocp
+	base   string
+	token  string
+	client *http.Client
+}
+
+func newReportPortalClient(endpoint, project, token string) *reportPortalClient {
+	return &reportPortalClient{
+		base:   strings.TrimSuffix(endpoint, "/") + "/v1/" + project,
+		token:  token,
+		client: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+type rpAttribute struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+type rpStartRequest struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	StartTime   string        `json:"startTime"`
+	Type        string        `json:"type,omitempty"`
+	Mode        string        `json:"mode,omitempty"`
+	LaunchUUID  string        `json:"launchUuid,omitempty"`
+	Attributes  []rpAttribute `json:"attributes,omitempty"`
+}
+
+type rpFinishRequest struct {
+	EndTime    string   `json:"endTime"`
+	Status     string   `json:"status,omitempty"`
+	LaunchUUID string   `json:"launchUuid,omitempty"`
+	Issue      *rpIssue `json:"issue,omitempty"`
+}
+
+type rpIssue struct {
+	IssueType string `json:"issueType"`
+}
+
+type rpLogRequest struct {
+	LaunchUUID string `json:"launchUuid"`
+	ItemUUID   string `json:"itemUuid,omitempty"`
+	Time       string `json:"time"`
+	Message    string `json:"message"`
+	Level      string `json:"level"`
+}
+
+type rpIDResponse struct {
+	ID string `json:"id"`
+}
+
+// The item statuses and log levels used by the uploader.
+const (
+	rpPassed  = "PASSED"
+	rpFailed  = "FAILED"
+	rpSkipped = "SKIPPED"
+
+	rpInfo  = "INFO"
+	rpWarn  = "WARN"
+	rpError = "ERROR"
+
+	// rpToInvestigate is the issue type of failures that have not been triaged
+	rpToInvestigate = "ti001"
+)
+
+func rpTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func (c *reportPortalClient) do(method, path string, body, into interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, c.base+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(c.token) > 0 {
+		req.Header.Set("Authorization", "bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	out, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(out)))
+	}
+	if into == nil {
+		return nil
+	}
+	if err := json.Unmarshal(out, into); err != nil {
+		return fmt.Errorf("%s %s returned an invalid response: %v", method, path, err)
+	}
+	return nil
+}
+
+// StartLaunch creates a launch and returns its UUID.
+func (c *reportPortalClient) StartLaunch(req *rpStartRequest) (string, error) {
+	var resp rpIDResponse
+	if err := c.do(http.MethodPost, "/launch", req, &resp); err != nil {
+		return "", fmt.Errorf("could not start launch: %v", err)
+	}
+	return resp.ID, nil
+}
+
+func (c *reportPortalClient) FinishLaunch(launch string, req *rpFinishRequest) error {
+	if err := c.do(http.MethodPut, "/launch/"+launch+"/finish", req, nil); err != nil {
+		return fmt.Errorf("could not finish launch: %v", err)
+	}
+	return nil
+}
+
+// StartItem creates an item under parent, or at the top of the launch if parent is
+// empty, and returns its UUID.
+func (c *reportPortalClient) StartItem(parent string, req *rpStartRequest) (string, error) {
+	path := "/item"
+	if len(parent) > 0 {
+		path += "/" + parent
+	}
+	var resp rpIDResponse
+	if err := c.do(http.MethodPost, path, req, &resp); err != nil {
+		return "", fmt.Errorf("could not start item %q: %v", req.Name, err)
+	}
+	return resp.ID, nil
+}
+
+func (c *reportPortalClient) FinishItem(item string, req *rpFinishRequest) error {
+	if err := c.do(http.MethodPut, "/item/"+item, req, nil); err != nil {
+		return fmt.Errorf("could not finish item: %v", err)
+	}
+	return nil
+}
+
+// Log attaches a message to an item, or to the launch if req.ItemUUID is empty.
+func (c *reportPortalClient) Log(req *rpLogRequest) error {
+	if err := c.do(http.MethodPost, "/log", req, nil); err != nil {
+		return fmt.Errorf("could not save log: %v", err)
+	}
+	return nil
+}
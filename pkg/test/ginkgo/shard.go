@@ -0,0 +1,119 @@
+package ginkgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+)
+
+// serialShard is the shard that runs every [Serial] test, so that serial tests from
+// different shards never overlap on a shared cluster.
+const serialShard = 0
+
+// shardTests returns the tests that belong to shard index of count. Without
+// durations each test is assigned by a hash of its name, which keeps a test on the
+// same shard as other tests are added or removed. With durations the tests are
+// assigned longest first to the least loaded shard, and tests without a recorded
+// duration are assumed to take the average duration. The result is deterministic
+// for the same tests and durations regardless of their order.
+func shardTests(tests []*testCase, count, index int, durations map[string]time.Duration) []*testCase {
+	if count <= 1 {
+		return tests
+	}
+
+	assignment := make(map[string]int)
+	var parallel []string
+	loads := make([]time.Duration, count)
+	for _, name := range uniqueTestNames(tests) {
+		if strings.Contains(name, "[Serial]") {
+			assignment[name] = serialShard
+			loads[serialShard] += durations[name]
+			continue
+		}
+		parallel = append(parallel, name)
+	}
+
+	if len(durations) == 0 {
+		for _, name := range parallel {
+			h := fnv.New32a()
+			h.Write([]byte(name))
+			assignment[name] = int(h.Sum32() % uint32(count))
+		}
+	} else {
+		var total time.Duration
+		var known int
+		for _, name := range parallel {
+			if d, ok := durations[name]; ok {
+				total += d
+				known++
+			}
+		}
+		var average time.Duration
+		if known > 0 {
+			average = total / time.Duration(known)
+		}
+		estimate := func(name string) time.Duration {
+			if d, ok := durations[name]; ok {
+				return d
+			}
+			return average
+		}
+		sort.SliceStable(parallel, func(i, j int) bool {
+			di, dj := estimate(parallel[i]), estimate(parallel[j])
+			if di != dj {
+				return di > dj
+			}
+			return parallel[i] < parallel[j]
+		})
+		for _, name := range parallel {
+			shard := 0
+			for i := 1; i < count; i++ {
+				if loads[i] < loads[shard] {
+					shard = i
+				}
+			}
+			assignment[name] = shard
+			loads[shard] += estimate(name)
+		}
+	}
+
+	var selected []*testCase
+	for _, test := range tests {
+		if assignment[test.name] == index {
+			selected = append(selected, test)
+		}
+	}
+	return selected
+}
+
+func uniqueTestNames(tests []*testCase) []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, test := range tests {
+		if _, ok := seen[test.name]; ok {
+			continue
+		}
+		seen[test.name] = struct{}{}
+		names = append(names, test.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateShard(count, index int) error {
+	if count < 0 {
+		return fmt.Errorf("--shard-count must be a positive number")
+	}
+	if count <= 1 {
+		if index != 0 {
+			return fmt.Errorf("--shard-index requires --shard-count")
+		}
+		return nil
+	}
+	if index < 0 || index >= count {
+		return fmt.Errorf("--shard-index must be between 0 and %d", count-1)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package ginkgo
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_shardTests(t *testing.T) {
+	var tests []*testCase
+	for i := 0; i < 20; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("test-%02d", i)})
+	}
+	tests = append(tests, &testCase{name: "serial-a [Serial]"}, &testCase{name: "serial-b [Serial]"})
+	reversed := make([]*testCase, len(tests))
+	for i, test := range tests {
+		reversed[len(tests)-1-i] = test
+	}
+
+	durations := map[string]time.Duration{
+		"test-00":           60 * time.Minute,
+		"test-01":           59 * time.Minute,
+		"serial-a [Serial]": 5 * time.Minute,
+	}
+	for i := 2; i < 15; i++ {
+		durations[fmt.Sprintf("test-%02d", i)] = time.Minute
+	}
+	for _, d := range []map[string]time.Duration{nil, durations} {
+		seen := make(map[string]int)
+		for index := 0; index < 3; index++ {
+			shard := shardTests(tests, 3, index, d)
+			if got := shardTests(reversed, 3, index, d); !reflect.DeepEqual(sortedNames(got), sortedNames(shard)) {
+				t.Errorf("shard %d depends on the order of tests: %v != %v", index, sortedNames(got), sortedNames(shard))
+			}
+			for _, test := range shard {
+				seen[test.name]++
+				if index != serialShard && test.name[:6] == "serial" {
+					t.Errorf("serial test %q assigned to shard %d", test.name, index)
+				}
+			}
+		}
+		if len(seen) != len(tests) {
+			t.Errorf("expected every test to be assigned once, got %v", seen)
+		}
+		for name, count := range seen {
+			if count != 1 {
+				t.Errorf("test %q assigned to %d shards", name, count)
+			}
+		}
+	}
+
+	// the two longest tests are balanced onto different shards
+	var longest []int
+	for index := 0; index < 3; index++ {
+		for _, test := range shardTests(tests, 3, index, durations) {
+			if test.name == "test-00" || test.name == "test-01" {
+				longest = append(longest, index)
+			}
+		}
+	}
+	if len(longest) != 2 || longest[0] == longest[1] {
+		t.Errorf("expected the longest tests on different shards, got %v", longest)
+	}
+}
+
+func sortedNames(tests []*testCase) []string {
+	return testNames(sortedTests(tests))
+}
@@ -0,0 +1,280 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/openshift-tests-private/pkg/monitor"
+)
+
+// soakConfidence is the z-score of the confidence interval of soak pass rates (95%).
+const soakConfidence = 1.96
+
+// soakResult counts the runs of a test during a soak and keeps the output of its
+// first failures.
+type soakResult struct {
+	test     *testCase
+	pass     int
+	fail     int
+	skip     int
+	duration time.Duration
+	failures []string
+}
+
+// runs returns the number of runs that passed or failed.
+func (r *soakResult) runs() int {
+	return r.pass + r.fail
+}
+
+// passRate returns the pass rate of the test and the bounds of its Wilson score
+// interval, which stays meaningful for the small number of runs and the pass rates
+// close to 100% of a soak.
+func (r *soakResult) passRate() (rate, low, high float64) {
+	n := float64(r.runs())
+	if n == 0 {
+		return 0, 0, 0
+	}
+	rate = float64(r.pass) / n
+	z2 := soakConfidence * soakConfidence
+	denominator := 1 + z2/n
+	center := (rate + z2/(2*n)) / denominator
+	spread := soakConfidence * math.Sqrt(rate*(1-rate)/n+z2/(4*n*n)) / denominator
+	return rate, math.Max(0, center-spread), math.Min(1, center+spread)
+}
+
+// soak runs the selected tests again and again and counts the results of each.
+type soak struct {
+	tests       []*testCase
+	parallelism int
+	durations   map[string]time.Duration
+	// untilFailure stops the soak after the first round with a failure.
+	untilFailure bool
+	// deadline is the time after which no more tests are started, if set.
+	deadline time.Time
+	// keepFailures is the number of failure outputs kept per test.
+	keepFailures int
+
+	rounds      int
+	interrupted int
+	results     map[string]*soakResult
+}
+
+func newSoak(tests []*testCase, parallelism int, durations map[string]time.Duration, untilFailure bool, deadline time.Time, keepFailures int) *soak {
+	s := &soak{
+		tests:        tests,
+		parallelism:  parallelism,
+		durations:    durations,
+		untilFailure: untilFailure,
+		deadline:     deadline,
+		keepFailures: keepFailures,
+		results:      make(map[string]*soakResult),
+	}
+	for _, test := range tests {
+		s.results[test.name] = &soakResult{test: test}
+	}
+	return s
+}
+
+// Run queues a copy of every test in each round, using the function newRound
+// returns to run them. Rounds are started until ctx is done, the deadline has
+// passed, no test passed or failed in a round or, if untilFailure is set, a test
+// failed. Failures of tests that were running when ctx was cancelled are not
+// counted.
+func (s *soak) Run(ctx context.Context, newRound func(round int) TestFunc) {
+	for ctx.Err() == nil && !s.expired() {
+		s.rounds++
+		run := newRound(s.rounds)
+		var tests []*testCase
+		for _, test := range s.tests {
+			tests = append(tests, test.clone())
+		}
+		q := newParallelTestQueue(tests, s.durations)
+		q.deadline = s.deadline
+		q.Execute(ctx, s.parallelism, func(ctx context.Context, test *testCase) {
+			run(ctx, test)
+			if test.failed && ctx.Err() != nil {
+				test.failed = false
+				s.interrupted++
+			}
+		})
+
+		failed, ran := false, false
+		for _, test := range tests {
+			result := s.results[test.name]
+			switch {
+			case test.success:
+				result.pass++
+				ran = true
+			case test.failed:
+				result.fail++
+				failed, ran = true, true
+				if len(result.failures) < s.keepFailures {
+					result.failures = append(result.failures, lastLinesUntil(string(test.out), 100, "fail ["))
+				}
+			case test.skipped:
+				result.skip++
+			default:
+				continue
+			}
+			result.duration += test.duration
+		}
+		// stop if the tests are all skipped, or the soak would never end
+		if !ran || (failed && s.untilFailure) {
+			return
+		}
+	}
+}
+
+func (s *soak) expired() bool {
+	return !s.deadline.IsZero() && !time.Now().Before(s.deadline)
+}
+
+// Results returns the result of every test, lowest pass rate first.
+func (s *soak) Results() []*soakResult {
+	var results []*soakResult
+	for _, result := range s.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.runs() == 0) != (b.runs() == 0) {
+			return b.runs() == 0
+		}
+		if rateA, rateB := float64(a.pass)*float64(b.runs()), float64(b.pass)*float64(a.runs()); rateA != rateB {
+			return rateA < rateB
+		}
+		return a.test.name < b.test.name
+	})
+	return results
+}
+
+// writeSoakResults prints the pass rate of every test and the kept output of the
+// tests that failed.
+func writeSoakResults(out io.Writer, results []*soakResult, rounds int, duration time.Duration) error {
+	fmt.Fprintf(out, "Soak results after %d rounds (%s):\n\n", rounds, duration)
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PASS RATE\t95% CI\tRUNS\tFAILED\tSKIPPED\tTEST")
+	for _, result := range results {
+		if result.runs() == 0 {
+			fmt.Fprintf(w, "-\t-\t0\t0\t%d\t%s\n", result.skip, result.test.name)
+			continue
+		}
+		rate, low, high := result.passRate()
+		fmt.Fprintf(w, "%.1f%%\t%.1f%%-%.1f%%\t%d\t%d\t%d\t%s\n", rate*100, low*100, high*100, result.runs(), result.fail, result.skip, result.test.name)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	for _, result := range results {
+		if result.fail == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "Failures of %q (first %d of %d):\n\n", result.test.name, len(result.failures), result.fail)
+		for i, failure := range result.failures {
+			fmt.Fprintf(out, "--- failure %d ---\n%s\n\n", i+1, failure)
+		}
+	}
+	return nil
+}
+
+// newSoakJUnitSuite reports a test case per test, which failed if any of its runs
+// failed. The counts and the pass rate are recorded as properties of the test case.
+func newSoakJUnitSuite(name string, results []*soakResult, duration time.Duration, properties []*TestSuiteProperty) *JUnitTestSuite {
+	s := &JUnitTestSuite{
+		Name:       name,
+		Duration:   duration.Seconds(),
+		Properties: properties,
+	}
+	for _, result := range results {
+		testCase := &JUnitTestCase{
+			Name:      result.test.name,
+			Classname: junitClassname(result.test),
+			Duration:  result.duration.Seconds(),
+			Properties: append([]*TestSuiteProperty{
+				{Name: "runs", Value: strconv.Itoa(result.runs())},
+				{Name: "failures", Value: strconv.Itoa(result.fail)},
+				{Name: "skips", Value: strconv.Itoa(result.skip)},
+			}, ownerProperties(result.test.owners)...),
+		}
+		rate, low, high := result.passRate()
+		summary := fmt.Sprintf("passed %d of %d runs", result.pass, result.runs())
+		if result.runs() > 0 {
+			summary += fmt.Sprintf(", pass rate %.1f%% (95%% CI %.1f%%-%.1f%%)", rate*100, low*100, high*100)
+			testCase.Properties = append(testCase.Properties, &TestSuiteProperty{Name: "pass-rate", Value: strconv.FormatFloat(rate, 'f', 4, 64)})
+		}
+		switch {
+		case result.fail > 0:
+			testCase.FailureOutput = &FailureOutput{
+				Message: summary,
+				Output:  fmt.Sprintf("%s\n\n%s", summary, strings.Join(result.failures, "\n\n---\n\n")),
+			}
+		case result.runs() == 0:
+			testCase.SkipMessage = &SkipMessage{Message: fmt.Sprintf("skipped in all %d runs", result.skip)}
+		default:
+			testCase.SystemOut = summary
+		}
+		s.addTestCase(testCase)
+	}
+	return s
+}
+
+// runSoak runs the tests in rounds until one fails with RepeatUntilFailure, or
+// until Soak has passed, and reports the pass rate of each test. Tests are not
+// retried and no diagnostics are collected.
+func (opt *Options) runSoak(ctx context.Context, suite *TestSuite, tests []*testCase, durations map[string]time.Duration, parallelism int, timeout time.Duration) error {
+	var cluster *clusterInfo
+	if len(opt.JUnitDir) > 0 {
+		cluster, _ = discoverClusterInfo(opt.kubeconfig)
+	}
+	properties := opt.junitProperties(suite.Name, parallelism, timeout, cluster)
+
+	m, err := monitor.StartWithKubeconfig(ctx, opt.kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var deadline time.Time
+	if opt.Soak > 0 {
+		deadline = start.Add(opt.Soak)
+	}
+	s := newSoak(tests, parallelism, durations, opt.RepeatUntilFailure, deadline, opt.SoakFailureOutputs)
+	s.Run(ctx, func(round int) TestFunc {
+		fmt.Fprintf(opt.Out, "Starting soak round %d (%s elapsed)\n\n", round, time.Now().Sub(start).Round(time.Second))
+		return newTestStatus(opt.Out, opt.IncludeSuccessOutput, len(tests), timeout, m, opt.AsEnv()).Run
+	})
+	duration := time.Now().Sub(start).Round(time.Second)
+	if s.interrupted > 0 {
+		fmt.Fprintf(opt.Out, "%d failures of tests that were interrupted are not counted\n\n", s.interrupted)
+	}
+
+	results := s.Results()
+	if err := writeSoakResults(opt.Out, results, s.rounds, duration); err != nil {
+		return err
+	}
+	if len(opt.JUnitDir) > 0 {
+		report := newSoakJUnitSuite("openshift-tests-private", results, duration, properties)
+		if err := writeJUnitReport("junit_e2e", report, opt.JUnitDir, opt.ErrOut); err != nil {
+			fmt.Fprintf(opt.Out, "error: Unable to write e2e JUnit results: %v", err)
+		}
+	}
+
+	var failed int
+	for _, result := range results {
+		if result.fail > 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed at least once in %d rounds (%s)", failed, len(results), s.rounds, duration)
+	}
+	return ctx.Err()
+}
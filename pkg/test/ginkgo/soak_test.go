@@ -0,0 +1,105 @@
+package ginkgo
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSoak(t *testing.T) {
+	const (
+		stable = "[sig-node] NODE Author:minmli-High-41579-Liveness probe"
+		flaky  = "[sig-node] NODE Author:pmali-High-11600-kubelet evicts pods"
+		sriov  = "[sig-networking] SDN Author:zzhao-High-25321-sriov"
+	)
+	newTests := func() []*testCase {
+		return []*testCase{{name: stable}, {name: flaky}, {name: sriov}}
+	}
+	// flaky fails in rounds 2 and 4
+	run := func(round int) TestFunc {
+		return func(ctx context.Context, test *testCase) {
+			test.duration = time.Second
+			switch {
+			case test.name == sriov:
+				test.skipped = true
+			case test.name == flaky && round%2 == 0:
+				test.failed = true
+				test.out = []byte("fail [round " + string(rune('0'+round)) + "]")
+			default:
+				test.success = true
+			}
+		}
+	}
+
+	s := newSoak(newTests(), 2, nil, true, time.Time{}, 1)
+	s.Run(context.Background(), run)
+	if s.rounds != 2 {
+		t.Fatalf("expected the soak to stop after the first failure in round 2, ran %d rounds", s.rounds)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s = newSoak(newTests(), 2, nil, false, time.Time{}, 1)
+	s.Run(ctx, func(round int) TestFunc {
+		if round == 5 {
+			cancel()
+		}
+		return run(round)
+	})
+	if s.rounds != 5 || s.interrupted != 0 {
+		t.Fatalf("expected 5 rounds until cancelled, ran %d with %d interrupted", s.rounds, s.interrupted)
+	}
+	results := s.Results()
+	if results[0].test.name != flaky || results[1].test.name != stable || results[2].test.name != sriov {
+		t.Fatalf("unexpected order %s, %s, %s", results[0].test.name, results[1].test.name, results[2].test.name)
+	}
+	if r := results[0]; r.pass != 3 || r.fail != 2 || len(r.failures) != 1 || r.failures[0] != "fail [round 2]" {
+		t.Errorf("unexpected result of the flaky test: %#v", r)
+	}
+	if r := results[2]; r.runs() != 0 || r.skip != 5 {
+		t.Errorf("unexpected result of the skipped test: %#v", r)
+	}
+
+	rate, low, high := results[0].passRate()
+	if rate != 0.6 || math.Abs(low-0.2307) > 0.001 || math.Abs(high-0.8824) > 0.001 {
+		t.Errorf("unexpected pass rate %f (%f-%f)", rate, low, high)
+	}
+	if _, low, high := results[1].passRate(); high != 1 || math.Abs(low-0.5655) > 0.001 {
+		t.Errorf("unexpected interval of the stable test %f-%f", low, high)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeSoakResults(buf, results, s.rounds, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	output := strings.Join(lines, "\n")
+	for _, s := range []string{"60.0% 23.1%-88.2% 5 2 0 " + flaky, "- - 0 0 5 " + sriov, "(first 1 of 2):\n\n--- failure 1 ---\nfail [round 2]"} {
+		if !strings.Contains(output, s) {
+			t.Errorf("output does not contain %q:\n%s", s, buf.String())
+		}
+	}
+
+	suite := newSoakJUnitSuite("openshift-tests-private", results, time.Minute, nil)
+	if suite.NumTests != 3 || suite.NumFailed != 1 || suite.NumSkipped != 1 {
+		t.Errorf("unexpected counts %d tests, %d failed, %d skipped", suite.NumTests, suite.NumFailed, suite.NumSkipped)
+	}
+	if message := suite.TestCases[0].FailureOutput.Message; message != "passed 3 of 5 runs, pass rate 60.0% (95% CI 23.1%-88.2%)" {
+		t.Errorf("unexpected failure message %q", message)
+	}
+}
+
+func TestSoak_allSkipped(t *testing.T) {
+	s := newSoak([]*testCase{{name: "skipped"}}, 1, nil, true, time.Time{}, 3)
+	s.Run(context.Background(), func(int) TestFunc {
+		return func(ctx context.Context, test *testCase) { test.skipped = true }
+	})
+	if s.rounds != 1 {
+		t.Fatalf("expected a soak of skipped tests to stop after one round, ran %d", s.rounds)
+	}
+}
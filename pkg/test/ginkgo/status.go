@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/openshift/openshift-tests-private/pkg/monitor"
+	exdiagnostics "github.com/openshift/openshift-tests-private/test/extended/util/diagnostics"
 )
 
 type testStatus struct {
@@ -21,6 +22,9 @@ type testStatus struct {
 	timeout time.Duration
 	monitor monitor.Interface
 	env     []string
+	events  *eventWriter
+	// diagnostics collects a bundle for each failed test if set
+	diagnostics *diagnostics
 
 	includeSuccessfulOutput bool
 
@@ -69,7 +73,15 @@ func (s *testStatus) OutputCommand(ctx context.Context, test *testCase) {
 }
 
 func (s *testStatus) Run(ctx context.Context, test *testCase) {
+	var bundle string
 	defer func() {
+		if test.failed && s.diagnostics != nil {
+			if err := s.diagnostics.Write(bundle, test, s.monitor); err != nil {
+				fmt.Fprintf(s.out, "error: Unable to write diagnostics for %q: %v\n", test.name, err)
+			}
+			test.diagnostics = bundle
+		}
+		s.events.TestFinished(test)
 		switch {
 		case test.success:
 			if s.includeSuccessfulOutput {
@@ -110,8 +122,17 @@ func (s *testStatus) Run(ctx context.Context, test *testCase) {
 	test.start = time.Now()
 	c := exec.Command(os.Args[0], "run-test", test.name)
 	c.Env = append(os.Environ(), s.env...)
+	if s.diagnostics != nil {
+		bundle = s.diagnostics.Reserve(test)
+		c.Env = append(c.Env, exdiagnostics.DirEnv+"="+s.diagnostics.Path(bundle))
+	}
 	s.Fprintf(fmt.Sprintf("started: (%s) %q\n\n", "%d/%d/%d", test.name))
-	out, err := runWithTimeout(ctx, c, s.timeout)
+	s.events.TestStarted(test)
+	timeout := s.timeout
+	if test.timeout > 0 {
+		timeout = test.timeout
+	}
+	out, err := runWithTimeout(ctx, c, timeout)
 	test.end = time.Now()
 
 	duration := test.end.Sub(test.start).Round(time.Second / 10)
@@ -0,0 +1,212 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SuiteDefinition declares a test suite in a YAML file instead of code. A test is
+// part of the suite if it matches any include expression (or there are none), no
+// exclude expression, carries every label in labels and none in excludeLabels, and
+// its sig, subteam and title metadata match the remaining predicates. For example:
+//
+//	name: node/nightly
+//	description: Node tests run every night.
+//	include:
+//	- '^\[sig-node\] NODE '
+//	exclude:
+//	- '\[Disruptive\]'
+//	importance: [Critical, High]
+//	excludePrefixes: [Longduration]
+//	parallelism: 5
+//	testTimeout: 30m
+//	maximumAllowedFlakes: 2
+type SuiteDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	Include       []string `json:"include,omitempty"`
+	Exclude       []string `json:"exclude,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	ExcludeLabels []string `json:"excludeLabels,omitempty"`
+
+	Sigs            []string `json:"sigs,omitempty"`
+	Subteams        []string `json:"subteams,omitempty"`
+	Importance      []string `json:"importance,omitempty"`
+	Authors         []string `json:"authors,omitempty"`
+	CaseIDs         []string `json:"caseIDs,omitempty"`
+	ExcludePrefixes []string `json:"excludePrefixes,omitempty"`
+
+	Parallelism          int    `json:"parallelism,omitempty"`
+	Count                int    `json:"count,omitempty"`
+	TestTimeout          string `json:"testTimeout,omitempty"`
+	MaximumAllowedFlakes int    `json:"maximumAllowedFlakes,omitempty"`
+}
+
+// loadSuiteFiles reads the suite definition at path, or every .yaml and .yml file
+// in path if it is a directory, in name order.
+func loadSuiteFiles(path string) ([]*TestSuite, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml":
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var suites []*TestSuite
+	names := make(map[string]string)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var def SuiteDefinition
+		if err := yaml.UnmarshalStrict(data, &def); err != nil {
+			return nil, fmt.Errorf("could not read suite file %s: %v", file, err)
+		}
+		suite, err := def.Compile()
+		if err != nil {
+			return nil, fmt.Errorf("suite file %s is invalid: %v", file, err)
+		}
+		if previous, ok := names[suite.Name]; ok {
+			return nil, fmt.Errorf("suite %q is defined in both %s and %s", suite.Name, previous, file)
+		}
+		names[suite.Name] = file
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+// mergeSuites returns the suites in defined followed by the additional suites. A
+// suite in additional replaces the suite of the same name in defined.
+func mergeSuites(defined, additional []*TestSuite) []*TestSuite {
+	names := make(map[string]struct{})
+	for _, suite := range additional {
+		names[suite.Name] = struct{}{}
+	}
+	var suites []*TestSuite
+	for _, suite := range defined {
+		if _, ok := names[suite.Name]; !ok {
+			suites = append(suites, suite)
+		}
+	}
+	return append(suites, additional...)
+}
+
+// Compile validates the definition and returns the equivalent test suite.
+func (d *SuiteDefinition) Compile() (*TestSuite, error) {
+	if len(d.Name) == 0 {
+		return nil, fmt.Errorf("name must be specified")
+	}
+	if d.Parallelism < 0 || d.Count < 0 || d.MaximumAllowedFlakes < 0 {
+		return nil, fmt.Errorf("parallelism, count and maximumAllowedFlakes may not be negative")
+	}
+	include, err := compileRegexes(d.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileRegexes(d.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	filter := MetadataFilter{
+		Importance:      append([]string(nil), d.Importance...),
+		Authors:         d.Authors,
+		CaseIDs:         d.CaseIDs,
+		ExcludePrefixes: d.ExcludePrefixes,
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	suite := &TestSuite{
+		Name:                 d.Name,
+		Description:          strings.TrimSpace(d.Description),
+		Parallelism:          d.Parallelism,
+		Count:                d.Count,
+		MaximumAllowedFlakes: d.MaximumAllowedFlakes,
+	}
+	if len(d.TestTimeout) > 0 {
+		if suite.TestTimeout, err = time.ParseDuration(d.TestTimeout); err != nil {
+			return nil, fmt.Errorf("testTimeout is invalid: %v", err)
+		}
+	}
+
+	suite.Matches = func(name string) bool {
+		if len(include) > 0 && !matchesAny(include, name) {
+			return false
+		}
+		if matchesAny(exclude, name) {
+			return false
+		}
+		if len(d.Labels) > 0 || len(d.ExcludeLabels) > 0 {
+			labels := testLabels(name)
+			for _, label := range d.Labels {
+				if !containsString(labels, label) {
+					return false
+				}
+			}
+			for _, label := range d.ExcludeLabels {
+				if containsString(labels, label) {
+					return false
+				}
+			}
+		}
+		if len(d.Sigs) > 0 || len(d.Subteams) > 0 {
+			sig, subteam := parseDescribe(name)
+			if len(d.Sigs) > 0 && !containsString(d.Sigs, sig) {
+				return false
+			}
+			if len(d.Subteams) > 0 && !containsString(d.Subteams, subteam) {
+				return false
+			}
+		}
+		metadata := metadataFromName(name)
+		return filter.Matches(&metadata)
+	}
+	return suite, nil
+}
+
+func compileRegexes(expressions []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, expr := range expressions {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("regular expression %q is invalid: %v", expr, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package ginkgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadSuiteFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "suites")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"node.yaml": `
+name: node/nightly
+description: Node tests run every night.
+include:
+- '^\[sig-node\] NODE '
+exclude:
+- '\[Disruptive\]'
+importance: [critical, High]
+excludePrefixes: [Longduration]
+parallelism: 5
+testTimeout: 30m
+maximumAllowedFlakes: 2
+`,
+		"serial.yml": `
+name: sdn/serial
+subteams: [SDN]
+labels: [Serial]
+excludeLabels: [Slow]
+count: 2
+`,
+		"README.md": "not a suite",
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	suites, err := loadSuiteFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suites) != 2 {
+		t.Fatalf("unexpected suites: %#v", suites)
+	}
+	node, sdn := suites[0], suites[1]
+	if node.Name != "node/nightly" || node.Parallelism != 5 || node.TestTimeout != 30*time.Minute || node.MaximumAllowedFlakes != 2 {
+		t.Errorf("unexpected suite: %#v", node)
+	}
+	if sdn.Name != "sdn/serial" || sdn.Count != 2 {
+		t.Errorf("unexpected suite: %#v", sdn)
+	}
+
+	tests := []struct {
+		suite *TestSuite
+		name  string
+		want  bool
+	}{
+		{suite: node, name: "[sig-node] NODE Probe Author:minmli-High-41579-Liveness probe", want: true},
+		{suite: node, name: "[sig-node] NODE Probe Author:minmli-Critical-41580-Startup probe [Serial]", want: true},
+		{suite: node, name: "[sig-node] NODE Probe Author:minmli-Low-41581-Exec probe", want: false},
+		{suite: node, name: "[sig-node] NODE Longduration-Author:pmali-High-11600-kubelet evicts pods", want: false},
+		{suite: node, name: "[sig-node] NODE Author:pmali-High-11601-reboot [Disruptive]", want: false},
+		{suite: node, name: "[sig-networking] SDN Author:zzhao-High-25321-dpdk", want: false},
+		{suite: sdn, name: "[sig-networking] SDN Author:zzhao-High-25321-dpdk [Serial]", want: true},
+		{suite: sdn, name: "[sig-networking] SDN Author:zzhao-High-25322-scale [Serial] [Slow]", want: false},
+		{suite: sdn, name: "[sig-networking] SDN Author:zzhao-High-25323-ping", want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.suite.Matches(tt.name); got != tt.want {
+			t.Errorf("%s.Matches(%q) = %t, want %t", tt.suite.Name, tt.name, got, tt.want)
+		}
+	}
+
+	merged := mergeSuites([]*TestSuite{{Name: "all"}, {Name: "node/nightly"}}, suites)
+	if len(merged) != 3 || merged[0].Name != "all" || merged[1] != node {
+		t.Errorf("unexpected merged suites: %#v", merged)
+	}
+}
+
+func TestLoadSuiteFiles_invalid(t *testing.T) {
+	tests := map[string]string{
+		"name must be specified":      "parallelism: 1\n",
+		"is invalid":                  "name: a\ninclude: ['[']\n",
+		"--importance must be one of": "name: a\nimportance: [urgent]\n",
+		"testTimeout is invalid":      "name: a\ntestTimeout: soon\n",
+		"could not read suite file":   "name: a\nparalelism: 1\n",
+	}
+	for want, contents := range tests {
+		t.Run(want, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "suite")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			f.WriteString(contents)
+			f.Close()
+			if _, err := loadSuiteFiles(f.Name()); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("expected error containing %q, got %v", want, err)
+			}
+		})
+	}
+}
@@ -18,9 +18,26 @@ type testCase struct {
 	name     string
 	spec     ginkgoSpec
 	location types.CodeLocation
+	metadata TestMetadata
+	sig      string
+	subteam  string
+	// owners are the approvers and reviewers of the nearest OWNERS file, if any
+	owners *Owners
+	// preCheck and postCheck pair the halves of an upgrade check
+	preCheck  *testCase
+	postCheck *testCase
+
+	// quarantine is set if failures of the test are reported as flakes
+	quarantine *QuarantineEntry
 
 	// identifies which tests can be run in parallel (ginkgo runs suites linearly)
 	testExclusion string
+	// exclusive holds a key for each [Exclusive:<resource>] label, no two tests
+	// holding the same key run at the same time
+	exclusive []string
+	// timeout overrides the suite timeout if set by a [Timeout:<duration>] label,
+	// unless the timeout was set explicitly for the run
+	timeout time.Duration
 
 	start    time.Time
 	end      time.Time
@@ -29,6 +46,8 @@ type testCase struct {
 	success  bool
 	failed   bool
 	skipped  bool
+	// diagnostics is the bundle of a failed run relative to the JUnit directory
+	diagnostics string
 
 	previous *testCase
 }
@@ -37,23 +56,69 @@ func newTestCase(spec ginkgoSpec) *testCase {
 	name := spec.ConcatenatedString()
 	name = strings.TrimPrefix(name, "[Top Level] ")
 	summary := spec.Summary("")
-	return &testCase{
+	sig, subteam := parseDescribe(name)
+	test := &testCase{
 		name:     name,
 		spec:     spec,
 		location: summary.ComponentCodeLocations[len(summary.ComponentCodeLocations)-1],
+		metadata: parseTestMetadata(summary.ComponentTexts[len(summary.ComponentTexts)-1]),
+		sig:      sig,
+		subteam:  subteam,
+	}
+	test.applyLabels()
+	return test
+}
+
+// applyLabels sets the timeout of the test from a [Timeout:<duration>] label, and
+// an exclusion key from each [Exclusive:<resource>] label so that tests sharing a
+// cluster singleton, like the default ingress controller or a machine config pool,
+// never run at the same time.
+func (t *testCase) applyLabels() {
+	labels := testLabels(t.name)
+	if value, ok := labelValue(labels, "Timeout"); ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			t.metadata.Warnings = append(t.metadata.Warnings, fmt.Sprintf("label [Timeout:%s] is not a positive duration", value))
+		} else {
+			t.timeout = timeout
+		}
+	}
+	for _, value := range labelValues(labels, "Exclusive") {
+		if len(value) > 0 {
+			t.exclusive = append(t.exclusive, "Exclusive:"+value)
+		}
+	}
+}
+
+// exclusions returns the keys the test holds while it runs.
+func (t *testCase) exclusions() []string {
+	if len(t.testExclusion) == 0 {
+		return t.exclusive
 	}
+	return append([]string{t.testExclusion}, t.exclusive...)
 }
 
 func (t *testCase) Retry() *testCase {
-	copied := &testCase{
+	copied := t.clone()
+	copied.previous = t
+	return copied
+}
+
+// clone returns a copy of the test that has not been run.
+func (t *testCase) clone() *testCase {
+	return &testCase{
 		name:          t.name,
 		spec:          t.spec,
 		location:      t.location,
+		metadata:      t.metadata,
+		sig:           t.sig,
+		subteam:       t.subteam,
+		owners:        t.owners,
+		quarantine:    t.quarantine,
 		testExclusion: t.testExclusion,
-
-		previous: t,
+		exclusive:     t.exclusive,
+		timeout:       t.timeout,
 	}
-	return copied
 }
 
 type TestSuite struct {
@@ -0,0 +1,49 @@
+package ginkgo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_testCase_applyLabels(t *testing.T) {
+	tests := []struct {
+		name          string
+		wantTimeout   time.Duration
+		wantExclusion []string
+		wantWarnings  int
+	}{
+		{
+			name:          "[sig-mco] MCO Author:rioliu-High-42390-Update a pool [Timeout:90m] [Exclusive:mcp-worker] [Serial]",
+			wantTimeout:   90 * time.Minute,
+			wantExclusion: []string{"Exclusive:mcp-worker"},
+		},
+		{
+			name:          "[sig-network-edge] Network_Edge Author:aiyengar-Medium-40747-Router sharding [Exclusive:ingress-default]",
+			wantExclusion: []string{"Exclusive:ingress-default"},
+		},
+		{
+			name:          "[sig-mco] MCO Author:rioliu-High-42391-Update the ingress on a pool [Exclusive:mcp-worker] [Exclusive:ingress-default] [Serial]",
+			wantExclusion: []string{"Exclusive:mcp-worker", "Exclusive:ingress-default"},
+		},
+		{
+			name:         "[sig-node] NODE Author:pmali-High-11600-kubelet [Timeout:soon]",
+			wantWarnings: 1,
+		},
+		{
+			name: "[sig-node] NODE Author:pmali-High-11601-kubelet",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &testCase{name: tt.name}
+			test.applyLabels()
+			if test.timeout != tt.wantTimeout || !reflect.DeepEqual(test.exclusive, tt.wantExclusion) || len(test.metadata.Warnings) != tt.wantWarnings {
+				t.Errorf("applyLabels() timeout=%s exclusion=%q warnings=%v", test.timeout, test.exclusive, test.metadata.Warnings)
+			}
+			if retry := test.Retry(); retry.timeout != test.timeout || !reflect.DeepEqual(retry.exclusive, test.exclusive) {
+				t.Errorf("Retry() did not preserve the labels")
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openshift/openshift-tests-private/test/extended/util/upgradestate"
+)
+
+const (
+	// preCheckUpgrade marks a test that prepares the cluster before an upgrade,
+	// either as a [PreChkUpgrade] label or as a PreChkUpgrade prefix of the title.
+	preCheckUpgrade = "PreChkUpgrade"
+	// postCheckUpgrade marks a test that verifies the cluster after an upgrade.
+	postCheckUpgrade = "PstChkUpgrade"
+	// clusterUpgradeLabel marks the test that upgrades the cluster.
+	clusterUpgradeLabel = "Feature:ClusterUpgrade"
+)
+
+type upgradePhase int
+
+const (
+	notUpgradePhase upgradePhase = iota
+	preCheckPhase
+	clusterUpgradePhase
+	postCheckPhase
+)
+
+// upgradePhaseOf returns the step of an upgrade run the test belongs to.
+func upgradePhaseOf(name string, m *TestMetadata) upgradePhase {
+	labels := testLabels(name)
+	switch {
+	case containsString(labels, clusterUpgradeLabel):
+		return clusterUpgradePhase
+	case containsString(labels, preCheckUpgrade) || m.HasPrefix(preCheckUpgrade):
+		return preCheckPhase
+	case containsString(labels, postCheckUpgrade) || m.HasPrefix(postCheckUpgrade):
+		return postCheckPhase
+	}
+	return notUpgradePhase
+}
+
+// IsUpgradeCheck returns true if the named test is a pre-upgrade or a post-upgrade
+// check.
+func IsUpgradeCheck(name string) bool {
+	m := metadataFromName(name)
+	switch upgradePhaseOf(name, &m) {
+	case preCheckPhase, postCheckPhase:
+		return true
+	}
+	return false
+}
+
+// upgradeCheckKey returns the key that pairs a pre-upgrade check with its
+// post-upgrade check, or an empty string if the test has no case ID.
+func upgradeCheckKey(name string, m *TestMetadata) string {
+	if len(m.CaseIDs) == 0 {
+		return ""
+	}
+	sig, _ := parseDescribe(name)
+	return upgradestate.CheckKey(sig, m.CaseIDs[0])
+}
+
+// upgradeChecks are the tests of an upgrade run in the order they are run.
+type upgradeChecks struct {
+	pre     []*testCase
+	upgrade []*testCase
+	post    []*testCase
+	other   []*testCase
+}
+
+// splitUpgradeChecks sorts the tests into the steps of an upgrade run, and pairs
+// each post-upgrade check with the pre-upgrade check of the same key.
+func splitUpgradeChecks(tests []*testCase) *upgradeChecks {
+	c := &upgradeChecks{}
+	preChecks := make(map[string]*testCase)
+	for _, test := range tests {
+		switch upgradePhaseOf(test.name, &test.metadata) {
+		case preCheckPhase:
+			c.pre = append(c.pre, test)
+			if key := upgradeCheckKey(test.name, &test.metadata); len(key) > 0 {
+				if _, ok := preChecks[key]; !ok {
+					preChecks[key] = test
+				}
+			}
+		case clusterUpgradePhase:
+			c.upgrade = append(c.upgrade, test)
+		case postCheckPhase:
+			c.post = append(c.post, test)
+		default:
+			c.other = append(c.other, test)
+		}
+	}
+	for _, test := range c.post {
+		pre, ok := preChecks[upgradeCheckKey(test.name, &test.metadata)]
+		if !ok || pre.postCheck != nil {
+			continue
+		}
+		pre.postCheck, test.preCheck = test, pre
+	}
+	return c
+}
+
+// Run runs the pre-upgrade checks and the other tests, then the upgrade, then the
+// post-upgrade checks. A post-upgrade check is skipped if the upgrade did not pass,
+// or if its pre-upgrade check did not pass.
+func (c *upgradeChecks) Run(execute func(tests []*testCase)) {
+	execute(append(append([]*testCase{}, c.other...), c.pre...))
+	execute(c.upgrade)
+
+	upgraded := true
+	for _, test := range c.upgrade {
+		upgraded = upgraded && test.success
+	}
+	var post []*testCase
+	for _, test := range c.post {
+		switch {
+		case !upgraded:
+			test.skipped = true
+			test.out = []byte("skip [upgrade]: the cluster upgrade did not pass")
+		case test.preCheck != nil && !test.preCheck.success:
+			test.skipped = true
+			test.out = []byte(fmt.Sprintf("skip [upgrade]: the pre-upgrade check did not pass: %q", test.preCheck.name))
+		default:
+			post = append(post, test)
+		}
+	}
+	execute(post)
+}
+
+var upgradeCheckMarker = regexp.MustCompile(`\b` + preCheckUpgrade + `\b`)
+
+// upgradeCheckFinished returns true if both halves of a pair of upgrade checks have
+// a result.
+func upgradeCheckFinished(pre *testCase) bool {
+	finished := func(t *testCase) bool { return t.success || t.failed || t.skipped }
+	return pre.postCheck != nil && finished(pre) && finished(pre.postCheck)
+}
+
+// junitUpgradeCheck reports a pre-upgrade check and its post-upgrade check as one
+// test case named after the pre-upgrade check with the marker replaced by
+// ChkUpgrade. It fails if either half failed and is skipped if both were skipped.
+func junitUpgradeCheck(pre *testCase) *JUnitTestCase {
+	post := pre.postCheck
+	result := &JUnitTestCase{
+		Name:      upgradeCheckMarker.ReplaceAllString(pre.name, "ChkUpgrade"),
+		Classname: junitClassname(pre),
+		Duration:  (pre.duration + post.duration).Seconds(),
+		SystemOut: fmt.Sprintf("pre-upgrade check %q:\n\n%s\n\npost-upgrade check %q:\n\n%s", pre.name, pre.out, post.name, post.out),
+	}
+	var failures []string
+	for _, half := range []struct {
+		title string
+		test  *testCase
+	}{{"pre-upgrade check", pre}, {"post-upgrade check", post}} {
+		if half.test.failed {
+			failures = append(failures, fmt.Sprintf("%s failed: %s", half.title, lastLinesUntil(string(half.test.out), 100, "fail [")))
+		}
+	}
+	switch {
+	case len(failures) > 0:
+		result.FailureOutput = &FailureOutput{Output: strings.Join(failures, "\n\n")}
+		if len(pre.diagnostics) > 0 {
+			result.FailureOutput.Output += fmt.Sprintf("\n\nDiagnostics: %s", pre.diagnostics)
+		}
+		if len(post.diagnostics) > 0 {
+			result.FailureOutput.Output += fmt.Sprintf("\n\nDiagnostics: %s", post.diagnostics)
+		}
+	case pre.skipped && post.skipped:
+		result.SkipMessage = &SkipMessage{Message: lastLinesUntil(string(pre.out), 100, "skip [")}
+	}
+	return result
+}
+
+// upgradeStateDir returns the directory of the upgrade state store: the value of
+// upgradestate.DirEnv if set, else the upgrade-state directory of junitDir, else a
+// new temporary directory.
+func upgradeStateDir(junitDir string) (string, error) {
+	if dir := os.Getenv(upgradestate.DirEnv); len(dir) > 0 {
+		return dir, nil
+	}
+	if len(junitDir) > 0 {
+		dir := filepath.Join(junitDir, "upgrade-state")
+		return dir, os.MkdirAll(dir, 0755)
+	}
+	return ioutil.TempDir("", "upgrade-state")
+}
@@ -0,0 +1,85 @@
+package ginkgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeChecks(t *testing.T) {
+	newTest := func(name string) *testCase {
+		return &testCase{name: name, metadata: metadataFromName(name)}
+	}
+	var (
+		upgrade    = newTest("[sig-updates][Feature:ClusterUpgrade] Cluster should remain functional during upgrade [Disruptive] [Serial] [Suite:openshift]")
+		preProxy   = newTest("[sig-imageregistry] Image_Registry NonPreRelease-PreChkUpgrade-Author:xiuwang-Critial-24345-Set proxy before upgrade")
+		postProxy  = newTest("[sig-imageregistry] Image_Registry NonPreRelease-PstChkUpgrade-Author:xiuwang-Critial-24345-Set proxy after upgrade")
+		preTags    = newTest("[sig-imageregistry] Image_Registry NonPreRelease-PreChkUpgrade-Author:wewang-High-41400-custom AWS tags prepare")
+		postTags   = newTest("[sig-imageregistry] Image_Registry NonPreRelease-PstChkUpgrade-Author:wewang-High-41400- custom AWS tags after upgrade")
+		preFIO     = newTest("[sig-isc] Security_and_Compliance Author:pdhamdhe-NonPreRelease-CPaasrunOnly-High-39254-Critical-42663-precheck [PreChkUpgrade]")
+		postFIO    = newTest("[sig-isc] Security_and_Compliance Author:pdhamdhe-NonPreRelease-CPaasrunOnly-High-39254-postcheck [PstChkUpgrade]")
+		postOnly   = newTest("[sig-imageregistry] Image_Registry NonPreRelease-PstChkUpgrade-Author:xiuwang-Medium-45346-Payload imagestream")
+		otherSig   = newTest("[sig-node] NODE NonPreRelease-PstChkUpgrade-Author:minmli-High-24345-same case ID")
+		unrelated  = newTest("[sig-node] NODE Author:minmli-High-41579-Liveness probe")
+		executions [][]string
+	)
+	checks := splitUpgradeChecks([]*testCase{postProxy, preProxy, unrelated, upgrade, preTags, postTags, preFIO, postFIO, postOnly, otherSig})
+	if preProxy.postCheck != postProxy || postProxy.preCheck != preProxy || preTags.postCheck != postTags || preFIO.postCheck != postFIO {
+		t.Fatalf("checks were not paired")
+	}
+	if postOnly.preCheck != nil || otherSig.preCheck != nil {
+		t.Fatalf("unexpected pairs %v %v", postOnly.preCheck, otherSig.preCheck)
+	}
+
+	checks.Run(func(tests []*testCase) {
+		executions = append(executions, testNames(tests))
+		for _, test := range tests {
+			switch test {
+			case preTags:
+				test.failed = true
+				test.out = []byte("fail [no bucket]")
+			case preFIO:
+				test.skipped = true
+			default:
+				test.success = true
+			}
+		}
+	})
+	want := [][]string{
+		{unrelated.name, preProxy.name, preTags.name, preFIO.name},
+		{upgrade.name},
+		{postProxy.name, postOnly.name, otherSig.name},
+	}
+	if !reflect.DeepEqual(executions, want) {
+		t.Fatalf("unexpected order of execution %v", executions)
+	}
+	if !postTags.skipped || !strings.Contains(string(postTags.out), "the pre-upgrade check did not pass") || !postFIO.skipped {
+		t.Errorf("expected the post-upgrade checks of failed and skipped pre-upgrade checks to be skipped")
+	}
+
+	if results := junitTestCases(postProxy); len(results) != 0 {
+		t.Errorf("expected a paired post-upgrade check to be reported with its pre-upgrade check, got %v", results)
+	}
+	results := junitTestCases(preProxy)
+	if len(results) != 1 || results[0].Name != "[sig-imageregistry] Image_Registry NonPreRelease-ChkUpgrade-Author:xiuwang-Critial-24345-Set proxy before upgrade" || results[0].FailureOutput != nil || results[0].SkipMessage != nil {
+		t.Errorf("unexpected result of a passing pair %#v", results)
+	}
+	results = junitTestCases(preTags)
+	if len(results) != 1 || results[0].FailureOutput == nil || results[0].FailureOutput.Output != "pre-upgrade check failed: fail [no bucket]" {
+		t.Errorf("unexpected result of a failing pair %#v", results[0])
+	}
+	results = junitTestCases(preFIO)
+	if len(results) != 1 || results[0].Name != "[sig-isc] Security_and_Compliance Author:pdhamdhe-NonPreRelease-CPaasrunOnly-High-39254-Critical-42663-precheck [ChkUpgrade]" || results[0].SkipMessage == nil {
+		t.Errorf("unexpected result of a skipped pair %#v", results[0])
+	}
+	if results := junitTestCases(postOnly); len(results) != 1 || results[0].Name != postOnly.name {
+		t.Errorf("expected an unpaired check to be reported on its own, got %v", results)
+	}
+
+	if !IsUpgradeCheck(preFIO.name) || !IsUpgradeCheck(postOnly.name) || IsUpgradeCheck(upgrade.name) || IsUpgradeCheck(unrelated.name) {
+		t.Errorf("unexpected IsUpgradeCheck")
+	}
+	if key := upgradeCheckKey(postFIO.name, &postFIO.metadata); key != "sig-isc-39254" {
+		t.Errorf("unexpected key %q", key)
+	}
+}
@@ -0,0 +1,136 @@
+package invariants
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned"
+)
+
+const deploymentConfigName = "upgrade"
+
+// DeploymentConfigUpgradeTest tests that a deployment config that rolled out before
+// a cluster upgrade is available after it, and that it can roll out again.
+type DeploymentConfigUpgradeTest struct{}
+
+// Name returns the tracking name of the test.
+func (DeploymentConfigUpgradeTest) Name() string { return "[sig-apps] deploymentconfig-upgrade" }
+
+// Setup creates a deployment config and waits for its first rollout.
+func (t *DeploymentConfigUpgradeTest) Setup(f *framework.Framework) {
+	labels := map[string]string{"app": deploymentConfigName}
+	client := appsv1client.NewForConfigOrDie(f.ClientConfig())
+
+	ginkgo.By("Creating a deployment config")
+	_, err := client.AppsV1().DeploymentConfigs(f.Namespace.Name).Create(&appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: deploymentConfigName, Namespace: f.Namespace.Name},
+		Spec: appsv1.DeploymentConfigSpec{
+			Replicas: 2,
+			Selector: labels,
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.DeploymentStrategyTypeRolling},
+			Triggers: appsv1.DeploymentTriggerPolicies{{Type: appsv1.DeploymentTriggerOnConfigChange}},
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:    "sleep",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"sh", "-c", "while true; do sleep 3600; done"},
+						Env:     []corev1.EnvVar{{Name: "ROLLOUT", Value: "before"}},
+					}},
+				},
+			},
+		},
+	})
+	framework.ExpectNoError(err)
+
+	ginkgo.By("Waiting for the first rollout")
+	t.waitForRollout(f, 1)
+}
+
+// Test waits for the upgrade to complete, and then verifies that the deployment
+// config is available and rolls out a change.
+func (t *DeploymentConfigUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade upgrades.UpgradeType) {
+	<-done
+	ginkgo.By("Checking the deployment config is available after the upgrade")
+	t.waitForRollout(f, 1)
+
+	ginkgo.By("Rolling out a change after the upgrade")
+	client := appsv1client.NewForConfigOrDie(f.ClientConfig())
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		dc, err := client.AppsV1().DeploymentConfigs(f.Namespace.Name).Get(deploymentConfigName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		dc.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "ROLLOUT", Value: "after"}}
+		_, err = client.AppsV1().DeploymentConfigs(f.Namespace.Name).Update(dc)
+		return err
+	})
+	framework.ExpectNoError(err)
+	t.waitForRollout(f, 2)
+}
+
+// Teardown cleans up any remaining resources.
+func (t *DeploymentConfigUpgradeTest) Teardown(f *framework.Framework) {
+	// rely on the namespace deletion to clean up everything
+}
+
+// waitForRollout waits until the version of the deployment config is the latest one,
+// it completed, and all of its replicas are available.
+func (t *DeploymentConfigUpgradeTest) waitForRollout(f *framework.Framework, version int64) {
+	client := appsv1client.NewForConfigOrDie(f.ClientConfig())
+	var state string
+	err := wait.PollImmediate(5*time.Second, 10*time.Minute, func() (bool, error) {
+		dc, err := client.AppsV1().DeploymentConfigs(f.Namespace.Name).Get(deploymentConfigName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		var complete bool
+		complete, state, err = rolloutComplete(f.ClientSet, dc, version)
+		return complete, err
+	})
+	framework.ExpectNoError(err, fmt.Sprintf("rollout %d of the deployment config: %s", version, state))
+}
+
+// rolloutComplete returns true if the version is the latest rollout of the deployment
+// config, it completed, and all replicas are available, and an error if the rollout
+// failed.
+func rolloutComplete(c kubernetes.Interface, dc *appsv1.DeploymentConfig, version int64) (bool, string, error) {
+	if dc.Status.ObservedGeneration < dc.Generation {
+		return false, "the deployment config was not observed", nil
+	}
+	if dc.Status.LatestVersion != version {
+		return false, fmt.Sprintf("the latest rollout is %d", dc.Status.LatestVersion), nil
+	}
+	rc, err := c.CoreV1().ReplicationControllers(dc.Namespace).Get(fmt.Sprintf("%s-%d", dc.Name, version), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, fmt.Sprintf("the replication controller of rollout %d does not exist", version), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	switch phase := appsv1.DeploymentStatus(rc.Annotations[appsv1.DeploymentStatusAnnotation]); phase {
+	case appsv1.DeploymentStatusComplete:
+	case appsv1.DeploymentStatusFailed:
+		return false, "", fmt.Errorf("rollout %d failed: %s", version, rc.Annotations[appsv1.DeploymentStatusReasonAnnotation])
+	default:
+		return false, fmt.Sprintf("the rollout is %s", phase), nil
+	}
+	if dc.Status.AvailableReplicas != dc.Spec.Replicas || dc.Status.UpdatedReplicas != dc.Spec.Replicas {
+		return false, fmt.Sprintf("%d of %d replicas are available", dc.Status.AvailableReplicas, dc.Spec.Replicas), nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,144 @@
+package invariants
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned"
+)
+
+const imageStreamName = "upgrade"
+
+// ImageStreamUpgradeTest tests that an image imported into an image stream can be
+// pulled from the integrated registry after a cluster upgrade, and that images can
+// still be imported and pulled after it.
+type ImageStreamUpgradeTest struct {
+	repository string
+}
+
+// Name returns the tracking name of the test.
+func (ImageStreamUpgradeTest) Name() string { return "[sig-imageregistry] imagestream-upgrade" }
+
+// Setup imports an image into an image stream and pulls it from the integrated
+// registry.
+func (t *ImageStreamUpgradeTest) Setup(f *framework.Framework) {
+	client := imagev1client.NewForConfigOrDie(f.ClientConfig())
+
+	ginkgo.By("Importing an image into an image stream")
+	_, err := client.ImageV1().ImageStreams(f.Namespace.Name).Create(&imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: imageStreamName, Namespace: f.Namespace.Name},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{importedTag("before")},
+		},
+	})
+	framework.ExpectNoError(err)
+	t.waitForImport(f, "before")
+	if len(t.repository) == 0 {
+		framework.Skipf("The integrated image registry is not available")
+	}
+
+	ginkgo.By("Pulling the image from the integrated registry")
+	t.testPod(f, "before")
+}
+
+// Test waits for the upgrade to complete, and then verifies that the image imported
+// before the upgrade can still be pulled, and that a new import can be pulled.
+func (t *ImageStreamUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade upgrades.UpgradeType) {
+	<-done
+	ginkgo.By("Pulling the image imported before the upgrade")
+	t.testPod(f, "before")
+
+	ginkgo.By("Importing an image after the upgrade")
+	client := imagev1client.NewForConfigOrDie(f.ClientConfig())
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		is, err := client.ImageV1().ImageStreams(f.Namespace.Name).Get(imageStreamName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		is.Spec.Tags = append(is.Spec.Tags, importedTag("after"))
+		_, err = client.ImageV1().ImageStreams(f.Namespace.Name).Update(is)
+		return err
+	})
+	framework.ExpectNoError(err)
+	t.waitForImport(f, "after")
+
+	ginkgo.By("Pulling the image imported after the upgrade")
+	t.testPod(f, "after")
+}
+
+// Teardown cleans up any remaining resources.
+func (t *ImageStreamUpgradeTest) Teardown(f *framework.Framework) {
+	// rely on the namespace deletion to clean up everything
+}
+
+// importedTag returns a tag that imports the busybox image and is pulled through the
+// integrated registry.
+func importedTag(name string) imagev1.TagReference {
+	return imagev1.TagReference{
+		Name:            name,
+		From:            &corev1.ObjectReference{Kind: "DockerImage", Name: imageutils.GetE2EImage(imageutils.BusyBox)},
+		ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.LocalTagReferencePolicy},
+	}
+}
+
+// waitForImport waits until the tag was imported, and records the repository of the
+// image stream in the integrated registry.
+func (t *ImageStreamUpgradeTest) waitForImport(f *framework.Framework, tag string) {
+	client := imagev1client.NewForConfigOrDie(f.ClientConfig())
+	err := wait.PollImmediate(5*time.Second, 5*time.Minute, func() (bool, error) {
+		is, err := client.ImageV1().ImageStreams(f.Namespace.Name).Get(imageStreamName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		t.repository = is.Status.DockerImageRepository
+		return tagImported(is, tag)
+	})
+	framework.ExpectNoError(err, fmt.Sprintf("importing tag %s", tag))
+}
+
+// tagImported returns true if the tag has an image, and an error if its import
+// failed.
+func tagImported(is *imagev1.ImageStream, tag string) (bool, error) {
+	for _, status := range is.Status.Tags {
+		if status.Tag != tag {
+			continue
+		}
+		for _, condition := range status.Conditions {
+			if condition.Type == imagev1.ImportSuccess && condition.Status == corev1.ConditionFalse {
+				return false, fmt.Errorf("import of tag %s failed: %s", tag, condition.Message)
+			}
+		}
+		return len(status.Items) > 0, nil
+	}
+	return false, nil
+}
+
+// testPod runs a pod with the image of the tag pulled from the integrated registry.
+func (t *ImageStreamUpgradeTest) testPod(f *framework.Framework, tag string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pull-" + tag,
+			Namespace: f.Namespace.Name,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    "pull",
+				Image:   fmt.Sprintf("%s:%s", t.repository, tag),
+				Command: []string{"echo", "pulled " + tag},
+			}},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+	f.TestContainerOutput("pull from the integrated registry", pod, 0, []string{"pulled " + tag})
+}
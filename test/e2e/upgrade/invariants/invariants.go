@@ -0,0 +1,71 @@
+// Package invariants contains the OpenShift tests that run while the cluster is
+// upgraded, next to the upstream tests of k8s.io/kubernetes/test/e2e/upgrades.
+package invariants
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+)
+
+// AllTests returns the OpenShift invariants.
+func AllTests() []upgrades.Test {
+	return []upgrades.Test{
+		&RouteUpgradeTest{},
+		&ImageStreamUpgradeTest{},
+		&DeploymentConfigUpgradeTest{},
+		&OAuthTokenUpgradeTest{},
+		&PersistentVolumeDataUpgradeTest{},
+	}
+}
+
+// checkUntilDone runs check every interval until done is closed, and fails the test
+// if check keeps failing for longer than tolerate. It returns how long check was
+// failing in total.
+func checkUntilDone(done <-chan struct{}, interval, tolerate time.Duration, description string, check func() error) time.Duration {
+	var unavailable time.Duration
+	var last, failingSince time.Time
+	for {
+		now := time.Now()
+		if !failingSince.IsZero() {
+			unavailable += now.Sub(last)
+		}
+		last = now
+
+		err := check()
+		switch {
+		case err == nil && !failingSince.IsZero():
+			framework.Logf("%s recovered after %s", description, now.Sub(failingSince).Round(time.Second))
+			failingSince = time.Time{}
+		case err == nil:
+		case failingSince.IsZero():
+			framework.Logf("%s failed: %v", description, err)
+			failingSince = now
+		case now.Sub(failingSince) > tolerate:
+			framework.Failf("%s failed for more than %s: %v", description, tolerate, err)
+		}
+
+		select {
+		case <-done:
+			if unavailable > 0 {
+				framework.Logf("%s failed for %s during the upgrade", description, unavailable.Round(time.Second))
+			}
+			return unavailable
+		case <-time.After(interval):
+		}
+	}
+}
+
+// expectWithin polls check until it succeeds, and fails the test if it did not
+// succeed within timeout.
+func expectWithin(timeout time.Duration, description string, check func() error) {
+	var lastErr error
+	if err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		lastErr = check()
+		return lastErr == nil, nil
+	}); err != nil {
+		framework.Failf("%s did not succeed within %s: %v", description, timeout, lastErr)
+	}
+}
@@ -0,0 +1,144 @@
+package invariants
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+func TestCheckUntilDone(t *testing.T) {
+	done := make(chan struct{})
+	calls := 0
+	unavailable := checkUntilDone(done, time.Millisecond, time.Minute, "check", func() error {
+		calls++
+		switch {
+		case calls == 5:
+			close(done)
+		case calls >= 2 && calls <= 3:
+			return fmt.Errorf("unavailable")
+		}
+		return nil
+	})
+	if calls != 5 {
+		t.Errorf("expected the check to run until done was closed, ran %d times", calls)
+	}
+	if unavailable <= 0 {
+		t.Errorf("expected the failed checks to be counted as unavailable")
+	}
+}
+
+func TestAdmittedHost(t *testing.T) {
+	admitted := []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue}}
+	rejected := []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: corev1.ConditionFalse}}
+	tests := []struct {
+		name    string
+		ingress []routev1.RouteIngress
+		host    string
+	}{
+		{name: "not admitted"},
+		{
+			name:    "admitted by the default router",
+			ingress: []routev1.RouteIngress{{Host: "a.apps", RouterName: "sharded", Conditions: admitted}, {Host: "b.apps", RouterName: "default", Conditions: admitted}},
+			host:    "b.apps",
+		},
+		{
+			name:    "rejected by the default router",
+			ingress: []routev1.RouteIngress{{Host: "a.apps", RouterName: "sharded", Conditions: admitted}, {Host: "b.apps", RouterName: "default", Conditions: rejected}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := &routev1.Route{Status: routev1.RouteStatus{Ingress: tt.ingress}}
+			if host := admittedHost(route, defaultRouter); host != tt.host {
+				t.Errorf("expected %q, got %q", tt.host, host)
+			}
+		})
+	}
+}
+
+func TestTagImported(t *testing.T) {
+	is := &imagev1.ImageStream{Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+		{Tag: "before", Items: []imagev1.TagEvent{{Image: "sha256:1"}}},
+		{Tag: "pending"},
+		{Tag: "failed", Conditions: []imagev1.TagEventCondition{{Type: imagev1.ImportSuccess, Status: corev1.ConditionFalse, Message: "not found"}}},
+	}}}
+	tests := []struct {
+		tag      string
+		imported bool
+		err      string
+	}{
+		{tag: "before", imported: true},
+		{tag: "pending"},
+		{tag: "missing"},
+		{tag: "failed", err: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			imported, err := tagImported(is, tt.tag)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil || imported != tt.imported {
+				t.Errorf("expected %t, got %t %v", tt.imported, imported, err)
+			}
+		})
+	}
+}
+
+func TestRolloutComplete(t *testing.T) {
+	rc := func(version int, phase appsv1.DeploymentStatus) *corev1.ReplicationController {
+		return &corev1.ReplicationController{ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("upgrade-%d", version),
+			Namespace:   "ns",
+			Annotations: map[string]string{appsv1.DeploymentStatusAnnotation: string(phase), appsv1.DeploymentStatusReasonAnnotation: "deployer pod failed"},
+		}}
+	}
+	dc := func(latest int64, available int32) *appsv1.DeploymentConfig {
+		return &appsv1.DeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "upgrade", Namespace: "ns", Generation: 2},
+			Spec:       appsv1.DeploymentConfigSpec{Replicas: 2},
+			Status:     appsv1.DeploymentConfigStatus{ObservedGeneration: 2, LatestVersion: latest, AvailableReplicas: available, UpdatedReplicas: available},
+		}
+	}
+	client := fake.NewSimpleClientset(rc(1, appsv1.DeploymentStatusComplete), rc(2, appsv1.DeploymentStatusFailed), rc(3, appsv1.DeploymentStatusRunning))
+	tests := []struct {
+		name     string
+		dc       *appsv1.DeploymentConfig
+		version  int64
+		complete bool
+		err      string
+	}{
+		{name: "complete", dc: dc(1, 2), version: 1, complete: true},
+		{name: "not available", dc: dc(1, 1), version: 1},
+		{name: "older version", dc: dc(1, 2), version: 2},
+		{name: "failed", dc: dc(2, 2), version: 2, err: "deployer pod failed"},
+		{name: "running", dc: dc(3, 2), version: 3},
+		{name: "not created", dc: dc(4, 2), version: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			complete, state, err := rolloutComplete(client, tt.dc, tt.version)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil || complete != tt.complete {
+				t.Errorf("expected %t, got %t %v (%s)", tt.complete, complete, err, state)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package invariants
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned"
+	userv1client "github.com/openshift/client-go/user/clientset/versioned"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+)
+
+// OAuthTokenUpgradeTest tests that a user can log in with an OAuth access token
+// during and after a cluster upgrade.
+type OAuthTokenUpgradeTest struct {
+	userName   string
+	clientName string
+	tokenName  string
+	config     *rest.Config
+}
+
+// Name returns the tracking name of the test.
+func (OAuthTokenUpgradeTest) Name() string { return "[sig-auth] oauth-token-upgrade" }
+
+// Setup creates a user and an OAuth access token for it, and logs in with the
+// token.
+func (t *OAuthTokenUpgradeTest) Setup(f *framework.Framework) {
+	t.userName = "e2e-upgrade-" + f.Namespace.Name
+	t.clientName = "e2e-upgrade-" + f.Namespace.Name
+	userClient := userv1client.NewForConfigOrDie(f.ClientConfig())
+	oauthClient := oauthv1client.NewForConfigOrDie(f.ClientConfig())
+
+	ginkgo.By("Creating a user and an OAuth access token")
+	user, err := userClient.UserV1().Users().Create(&userv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: t.userName},
+	})
+	framework.ExpectNoError(err)
+	_, err = oauthClient.OauthV1().OAuthClients().Create(&oauthv1.OAuthClient{
+		ObjectMeta:  metav1.ObjectMeta{Name: t.clientName},
+		GrantMethod: oauthv1.GrantHandlerAuto,
+	})
+	framework.ExpectNoError(err)
+	privToken, pubToken := exutil.GenerateOAuthTokenPair()
+	token, err := oauthClient.OauthV1().OAuthAccessTokens().Create(&oauthv1.OAuthAccessToken{
+		ObjectMeta:  metav1.ObjectMeta{Name: pubToken},
+		ClientName:  t.clientName,
+		UserName:    t.userName,
+		UserUID:     string(user.UID),
+		Scopes:      []string{"user:info"},
+		RedirectURI: "https://localhost:8443/oauth/token/implicit",
+	})
+	framework.ExpectNoError(err)
+	t.tokenName = token.Name
+
+	t.config = rest.AnonymousClientConfig(f.ClientConfig())
+	t.config.BearerToken = privToken
+	t.config.Timeout = 10 * time.Second
+
+	ginkgo.By("Logging in with the token")
+	expectWithin(time.Minute, "logging in with the OAuth access token", t.login)
+}
+
+// Test checks that the token logs in the user during the upgrade, and after it.
+func (t *OAuthTokenUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade upgrades.UpgradeType) {
+	ginkgo.By("Continuously logging in with the token during the upgrade")
+	checkUntilDone(done, 5*time.Second, 2*time.Minute, "logging in with the OAuth access token", t.login)
+
+	ginkgo.By("Logging in with the token after the upgrade")
+	expectWithin(5*time.Minute, "logging in with the OAuth access token", t.login)
+}
+
+// Teardown deletes the user, the OAuth client and the token, which are not
+// namespaced.
+func (t *OAuthTokenUpgradeTest) Teardown(f *framework.Framework) {
+	oauthClient := oauthv1client.NewForConfigOrDie(f.ClientConfig())
+	if len(t.tokenName) > 0 {
+		if err := oauthClient.OauthV1().OAuthAccessTokens().Delete(t.tokenName, nil); err != nil {
+			framework.Logf("error deleting OAuth access token: %v", err)
+		}
+	}
+	if err := oauthClient.OauthV1().OAuthClients().Delete(t.clientName, nil); err != nil {
+		framework.Logf("error deleting OAuth client %s: %v", t.clientName, err)
+	}
+	if err := userv1client.NewForConfigOrDie(f.ClientConfig()).UserV1().Users().Delete(t.userName, nil); err != nil {
+		framework.Logf("error deleting user %s: %v", t.userName, err)
+	}
+}
+
+// login returns an error unless the API server identifies the token as the user.
+func (t *OAuthTokenUpgradeTest) login() error {
+	client, err := userv1client.NewForConfig(t.config)
+	if err != nil {
+		return err
+	}
+	user, err := client.UserV1().Users().Get("~", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if user.Name != t.userName {
+		return fmt.Errorf("the token identifies user %s instead of %s", user.Name, t.userName)
+	}
+	return nil
+}
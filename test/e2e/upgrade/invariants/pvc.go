@@ -0,0 +1,136 @@
+package invariants
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2edeploy "k8s.io/kubernetes/test/e2e/framework/deployment"
+	e2epv "k8s.io/kubernetes/test/e2e/framework/pv"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+const (
+	pvcName     = "upgrade"
+	pvcDataFile = "/data/upgrade"
+)
+
+// PersistentVolumeDataUpgradeTest tests that a workload keeps the data it wrote to
+// a persistent volume claim of the default storage class across a cluster upgrade,
+// which moves it to other nodes as they are drained.
+type PersistentVolumeDataUpgradeTest struct {
+	data     string
+	selector labels.Selector
+}
+
+// Name returns the tracking name of the test.
+func (PersistentVolumeDataUpgradeTest) Name() string { return "[sig-storage] pvc-data-upgrade" }
+
+// Setup creates a claim of the default storage class and a deployment that writes
+// data to it.
+func (t *PersistentVolumeDataUpgradeTest) Setup(f *framework.Framework) {
+	ns := f.Namespace.Name
+	if _, err := e2epv.GetDefaultStorageClassName(f.ClientSet); err != nil {
+		framework.Skipf("The cluster has no default storage class: %v", err)
+	}
+
+	ginkgo.By("Creating a persistent volume claim of the default storage class")
+	_, err := f.ClientSet.CoreV1().PersistentVolumeClaims(ns).Create(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: ns},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	})
+	framework.ExpectNoError(err)
+
+	ginkgo.By("Creating a deployment that writes data to the claim")
+	t.data = string(uuid.NewUUID())
+	labelSet := map[string]string{"app": pvcName}
+	t.selector = labels.SelectorFromSet(labelSet)
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: ns},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labelSet},
+			// the claim can only be mounted on one node at a time
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labelSet},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "writer",
+						Image: imageutils.GetE2EImage(imageutils.BusyBox),
+						// the data is only written once, so a pod that lost it cannot restore it
+						Command: []string{"sh", "-c", fmt.Sprintf("test -f %[1]s || echo %[2]s > %[1]s; while true; do sleep 3600; done", pvcDataFile, t.data)},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "data",
+							MountPath: "/data",
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName},
+						},
+					}},
+				},
+			},
+		},
+	}
+	deployment, err = f.ClientSet.AppsV1().Deployments(ns).Create(deployment)
+	framework.ExpectNoError(err)
+	framework.ExpectNoError(e2edeploy.WaitForDeploymentComplete(f.ClientSet, deployment))
+
+	ginkgo.By("Reading the data from the claim")
+	expectWithin(time.Minute, "reading the data from the claim", func() error { return t.checkData(f) })
+}
+
+// Test waits for the upgrade to complete, and then verifies that the workload is
+// running and reads the data it wrote before the upgrade.
+func (t *PersistentVolumeDataUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade upgrades.UpgradeType) {
+	<-done
+	ginkgo.By("Reading the data from the claim after the upgrade")
+	expectWithin(10*time.Minute, "reading the data from the claim", func() error { return t.checkData(f) })
+}
+
+// Teardown cleans up any remaining resources.
+func (t *PersistentVolumeDataUpgradeTest) Teardown(f *framework.Framework) {
+	// rely on the namespace deletion to clean up everything
+}
+
+// checkData reads the data file in the running pod of the deployment and compares
+// it with the data written in Setup.
+func (t *PersistentVolumeDataUpgradeTest) checkData(f *framework.Framework) error {
+	pods, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).List(metav1.ListOptions{LabelSelector: t.selector.String()})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		stdout, stderr, err := f.ExecShellInPodWithFullOutput(pod.Name, "cat "+pvcDataFile)
+		if err != nil {
+			return fmt.Errorf("unable to read %s in pod %s: %v: %s", pvcDataFile, pod.Name, err, stderr)
+		}
+		if data := strings.TrimSpace(stdout); data != t.data {
+			framework.Failf("The claim lost its data, %s contains %q instead of %q", pvcDataFile, data, t.data)
+		}
+		return nil
+	}
+	return fmt.Errorf("no pod of the deployment is running")
+}
@@ -0,0 +1,155 @@
+package invariants
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/onsi/ginkgo"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2edeploy "k8s.io/kubernetes/test/e2e/framework/deployment"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned"
+)
+
+const (
+	routeName = "upgrade"
+	routePort = 8080
+	// defaultRouter is the name the default ingress controller admits routes with.
+	defaultRouter = "default"
+)
+
+// RouteUpgradeTest tests that a route served by the default ingress controller
+// stays reachable during and after a cluster upgrade.
+type RouteUpgradeTest struct {
+	host string
+}
+
+// Name returns the tracking name of the test.
+func (RouteUpgradeTest) Name() string { return "[sig-network-edge] route-upgrade" }
+
+// Setup creates a deployment behind a service and a route, and waits until the
+// default ingress controller serves the route.
+func (t *RouteUpgradeTest) Setup(f *framework.Framework) {
+	ns := f.Namespace.Name
+	labels := map[string]string{"app": routeName}
+
+	ginkgo.By("Creating a deployment serving HTTP")
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: routeName, Namespace: ns},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "server",
+						Image: imageutils.GetE2EImage(imageutils.Agnhost),
+						Args:  []string{"netexec", fmt.Sprintf("--http-port=%d", routePort)},
+						Ports: []corev1.ContainerPort{{ContainerPort: routePort}},
+						ReadinessProbe: &corev1.Probe{
+							Handler: corev1.Handler{
+								HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(routePort)},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+	deployment, err := f.ClientSet.AppsV1().Deployments(ns).Create(deployment)
+	framework.ExpectNoError(err)
+	framework.ExpectNoError(e2edeploy.WaitForDeploymentComplete(f.ClientSet, deployment))
+
+	ginkgo.By("Creating a service and a route")
+	_, err = f.ClientSet.CoreV1().Services(ns).Create(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: routeName, Namespace: ns},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Port: routePort, TargetPort: intstr.FromInt(routePort)}},
+		},
+	})
+	framework.ExpectNoError(err)
+	routeClient := routev1client.NewForConfigOrDie(f.ClientConfig())
+	_, err = routeClient.RouteV1().Routes(ns).Create(&routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: routeName, Namespace: ns},
+		Spec: routev1.RouteSpec{
+			To:   routev1.RouteTargetReference{Kind: "Service", Name: routeName},
+			Port: &routev1.RoutePort{TargetPort: intstr.FromInt(routePort)},
+		},
+	})
+	framework.ExpectNoError(err)
+
+	ginkgo.By("Waiting for the default ingress controller to admit the route")
+	err = wait.PollImmediate(5*time.Second, 5*time.Minute, func() (bool, error) {
+		route, err := routeClient.RouteV1().Routes(ns).Get(routeName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		t.host = admittedHost(route, defaultRouter)
+		return len(t.host) > 0, nil
+	})
+	framework.ExpectNoError(err, "the default ingress controller did not admit the route")
+
+	ginkgo.By("Waiting for the route to be reachable")
+	expectWithin(5*time.Minute, fmt.Sprintf("GET http://%s", t.host), t.get)
+}
+
+// Test checks that the route stays reachable during the upgrade, and that it is
+// reachable after it.
+func (t *RouteUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade upgrades.UpgradeType) {
+	ginkgo.By("Continuously requesting the route during the upgrade")
+	checkUntilDone(done, 2*time.Second, 2*time.Minute, fmt.Sprintf("GET http://%s", t.host), t.get)
+
+	ginkgo.By("Requesting the route after the upgrade")
+	expectWithin(5*time.Minute, fmt.Sprintf("GET http://%s", t.host), t.get)
+}
+
+// Teardown cleans up any remaining resources.
+func (t *RouteUpgradeTest) Teardown(f *framework.Framework) {
+	// rely on the namespace deletion to clean up everything
+}
+
+var routeHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// get requests the hostname of a server pod through the route.
+func (t *RouteUpgradeTest) get() error {
+	resp, err := routeHTTPClient.Get(fmt.Sprintf("http://%s/hostname", t.host))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// admittedHost returns the host the named router admitted the route with, or an
+// empty string if it did not admit the route.
+func admittedHost(route *routev1.Route, router string) string {
+	for _, ingress := range route.Status.Ingress {
+		if ingress.RouterName != router {
+			continue
+		}
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				return ingress.Host
+			}
+		}
+	}
+	return ""
+}
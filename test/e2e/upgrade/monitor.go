@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -96,29 +97,86 @@ func (m *versionMonitor) ShouldReboot() []string {
 	return nil
 }
 
-func (m *versionMonitor) ShouldUpgradeAbort(abortAt int) bool {
-	if abortAt == 0 {
-		return false
+// abortTrigger is the state of the cluster operators at which an upgrade is rolled
+// back to the previous version.
+type abortTrigger struct {
+	// percent of the operators that have updated, or upgradeAbortAtRandom
+	percent int
+	// operator has updated, or reports condition with status if condition is set
+	operator  string
+	condition configv1.ClusterStatusConditionType
+	status    configv1.ConditionStatus
+}
+
+func (t abortTrigger) enabled() bool {
+	return t.percent != 0 || len(t.operator) > 0
+}
+
+func (t abortTrigger) String() string {
+	switch {
+	case len(t.condition) > 0:
+		return fmt.Sprintf("operator %s reports %s=%s", t.operator, t.condition, t.status)
+	case len(t.operator) > 0:
+		return fmt.Sprintf("operator %s has upgraded", t.operator)
+	case t.percent == upgradeAbortAtRandom:
+		return "a random percentage of operators have upgraded"
+	default:
+		return fmt.Sprintf("%d%% of operators have upgraded", t.percent)
 	}
-	coList, err := m.client.ConfigV1().ClusterOperators().List(metav1.ListOptions{})
-	if err != nil {
-		framework.Logf("Unable to retrieve cluster operators, cannot check completion percentage")
-		return false
+}
+
+// reached returns true if the operators are in the state of the trigger, and a
+// summary of their state.
+func (t abortTrigger) reached(operators []configv1.ClusterOperator, oldVersion, newVersion string) (bool, string) {
+	if len(t.operator) > 0 {
+		for _, item := range operators {
+			if item.Name != t.operator {
+				continue
+			}
+			if len(t.condition) > 0 {
+				c := findCondition(item.Status.Conditions, t.condition)
+				if c == nil {
+					return false, fmt.Sprintf("operator %s does not report %s", t.operator, t.condition)
+				}
+				return c.Status == t.status, fmt.Sprintf("operator %s reports %s=%s", t.operator, t.condition, c.Status)
+			}
+			version := findVersion(item.Status.Versions, "operator", oldVersion, newVersion)
+			return version == "<new>", fmt.Sprintf("operator %s is at version %s", t.operator, version)
+		}
+		return false, fmt.Sprintf("operator %s does not exist", t.operator)
 	}
 
 	changed := 0
-	for _, item := range coList.Items {
-		if findVersion(item.Status.Versions, "operator", m.oldVersion, m.lastCV.Status.Desired.Version) != "<old>" {
+	for _, item := range operators {
+		if findVersion(item.Status.Versions, "operator", oldVersion, newVersion) != "<old>" {
 			changed++
 		}
 	}
-	percent := float64(changed) / float64(len(coList.Items))
-	if percent < float64(abortAt)/100 {
+	summary := fmt.Sprintf("upgraded %d/%d operators", changed, len(operators))
+	if len(operators) == 0 {
+		return false, summary
+	}
+	percent := float64(changed) / float64(len(operators))
+	return percent >= float64(t.percent)/100, summary
+}
+
+func (m *versionMonitor) ShouldUpgradeAbort(abortAt abortTrigger) bool {
+	if !abortAt.enabled() {
+		return false
+	}
+	coList, err := m.client.ConfigV1().ClusterOperators().List(metav1.ListOptions{})
+	if err != nil {
+		framework.Logf("Unable to retrieve cluster operators, cannot check whether %s", abortAt)
+		return false
+	}
+
+	reached, summary := abortAt.reached(coList.Items, m.oldVersion, m.lastCV.Status.Desired.Version)
+	if !reached {
 		return false
 	}
 
 	framework.Logf("-------------------------------------------------------")
-	framework.Logf("Upgraded %d/%d operators, beginning controlled rollback", changed, len(coList.Items))
+	framework.Logf("%s, beginning controlled rollback", strings.ToUpper(summary[:1])+summary[1:])
 	return true
 }
 
@@ -0,0 +1,64 @@
+package upgrade
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestAbortTriggerReached(t *testing.T) {
+	operator := func(name, version string, conditions ...configv1.ClusterOperatorStatusCondition) configv1.ClusterOperator {
+		return configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: configv1.ClusterOperatorStatus{
+				Versions:   []configv1.OperandVersion{{Name: "operator", Version: version}},
+				Conditions: conditions,
+			},
+		}
+	}
+	progressing := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorProgressing, Status: configv1.ConditionTrue}
+	operators := []configv1.ClusterOperator{
+		operator("etcd", "4.7.0"),
+		operator("network", "4.6.9", progressing),
+		operator("machine-config", "4.6.9"),
+		operator("dns", "4.6.9"),
+	}
+	tests := []struct {
+		name    string
+		trigger abortTrigger
+		reached bool
+		summary string
+	}{
+		{name: "percent reached", trigger: abortTrigger{percent: 25}, reached: true, summary: "upgraded 1/4 operators"},
+		{name: "percent not reached", trigger: abortTrigger{percent: 50}, summary: "upgraded 1/4 operators"},
+		{name: "operator upgraded", trigger: abortTrigger{operator: "etcd"}, reached: true, summary: "operator etcd is at version <new>"},
+		{name: "operator not upgraded", trigger: abortTrigger{operator: "machine-config"}, summary: "operator machine-config is at version <old>"},
+		{name: "missing operator", trigger: abortTrigger{operator: "missing"}, summary: "operator missing does not exist"},
+		{
+			name:    "condition reached",
+			trigger: abortTrigger{operator: "network", condition: configv1.OperatorProgressing, status: configv1.ConditionTrue},
+			reached: true,
+			summary: "operator network reports Progressing=True",
+		},
+		{
+			name:    "condition status differs",
+			trigger: abortTrigger{operator: "network", condition: configv1.OperatorProgressing, status: configv1.ConditionFalse},
+			summary: "operator network reports Progressing=True",
+		},
+		{
+			name:    "condition not reported",
+			trigger: abortTrigger{operator: "dns", condition: configv1.OperatorDegraded, status: configv1.ConditionTrue},
+			summary: "operator dns does not report Degraded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached, summary := tt.trigger.reached(operators, "4.6.9", "4.7.0")
+			if reached != tt.reached || summary != tt.summary {
+				t.Errorf("expected %t %q, got %t %q", tt.reached, tt.summary, reached, summary)
+			}
+		})
+	}
+}
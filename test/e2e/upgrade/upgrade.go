@@ -2,21 +2,27 @@ package upgrade
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	g "github.com/onsi/ginkgo"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/kubernetes/test/e2e/cloud/gcp"
 	"k8s.io/kubernetes/test/e2e/framework"
 	"k8s.io/kubernetes/test/e2e/upgrades"
 	apps "k8s.io/kubernetes/test/e2e/upgrades/apps"
@@ -24,6 +30,9 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+
+	"github.com/openshift/openshift-tests-private/test/e2e/upgrade/invariants"
+	"github.com/openshift/openshift-tests-private/test/extended/util/disruption"
 )
 
 func AllTests() []upgrades.Test {
@@ -46,22 +55,134 @@ func AllTests() []upgrades.Test {
 	}
 }
 
+// OpenShiftTests returns the OpenShift tests that can run during an upgrade, which
+// are selected with the tests option of the upgrade suites.
+func OpenShiftTests() []upgrades.Test {
+	return invariants.AllTests()
+}
+
+// The upgrade step of run-upgrade. Tests labeled [PreChkUpgrade] run before it and
+// tests labeled [PstChkUpgrade] after it.
+var _ = g.Describe("[sig-updates][Feature:ClusterUpgrade]", func() {
+	defer g.GinkgoRecover()
+	f := framework.NewDefaultFramework("cluster-upgrade")
+	f.SkipNamespaceCreation = true
+	f.SkipPrivilegedPSPBinding = true
+
+	g.It("Cluster should remain functional during upgrade [Disruptive] [Serial] [Suite:openshift]", func() {
+		config, err := framework.LoadConfig()
+		framework.ExpectNoError(err)
+		client := configv1client.NewForConfigOrDie(config)
+		dynamicClient := dynamic.NewForConfigOrDie(config)
+		upgCtx, err := getUpgradeContext(client, gcp.GetUpgradeTarget(), gcp.GetUpgradeImage())
+		framework.ExpectNoError(err, "determining what to upgrade to")
+
+		if upgradeEUS && upgradeAbortAt.enabled() {
+			framework.Failf("abort-at cannot be combined with eus=true")
+		}
+
+		// every hop is reported as its own test case while the invariants run across all of them
+		hops := upgCtx.Versions[1:]
+		var steps []disruption.Step
+		for i := range hops {
+			hop := hops[i]
+			var name string
+			if len(hops) > 1 {
+				name = fmt.Sprintf("hop %d of %d to %s", i+1, len(hops), hopString(hop))
+			}
+			steps = append(steps, hopSteps(name, hopString(hop), func() (bool, error) {
+				return clusterUpgrade(client, dynamicClient, config, hop)
+			})...)
+		}
+		if upgradeEUS {
+			pools := &workerPools{dc: dynamicClient}
+			steps, err = eusSteps(pools, steps)
+			framework.ExpectNoError(err, "planning the EUS upgrade")
+			// leave the workers able to update if a hop fails and the unpause step is skipped
+			defer func() {
+				if _, err := pools.unpause(); err != nil {
+					framework.Logf("error unpausing worker pools: %v", err)
+				}
+			}()
+		}
+		disruption.RunSteps("Cluster upgrade", "upgrade",
+			disruption.TestData{UpgradeType: upgrades.ClusterUpgrade, UpgradeContext: *upgCtx},
+			upgradeTests,
+			steps,
+		)
+	})
+})
+
 var (
 	upgradeTests               = []upgrades.Test{}
-	upgradeAbortAt             int
+	upgradeAbortAt             abortTrigger
 	upgradeDisruptRebootPolicy string
+	upgradeChannel             string
+	upgradeEUS                 bool
 )
 
 // upgradeAbortAtRandom is a special value indicating the abort should happen at a random percentage
 // between (0,100].
 const upgradeAbortAtRandom = -1
 
+const (
+	// hopDuration is how long the cluster may take to reach the version of a hop,
+	// doubled if the hop is rolled back.
+	hopDuration = 75 * time.Minute
+	// hopPoolsDuration is how long the pools may take to update after a hop.
+	hopPoolsDuration = 30 * time.Minute
+	// hopStartDuration covers picking the update and the cluster acknowledging it.
+	hopStartDuration = 10 * time.Minute
+	// eusPoolsDuration is how long the unpaused pools may take to update after an
+	// EUS upgrade.
+	eusPoolsDuration = 60 * time.Minute
+	// invariantsDuration covers setting up and tearing down the invariant tests.
+	invariantsDuration = 30 * time.Minute
+)
+
+// TestTimeout returns how long the upgrade test may take to upgrade through the
+// comma-separated targets or images, with the options set on this package, so that
+// the test is not stopped while a hop is still within its own time limits.
+func TestTimeout(upgradeTarget, upgradeImage string) (time.Duration, error) {
+	hops, err := upgradeHops(upgradeTarget, upgradeImage, upgradeChannel)
+	if err != nil {
+		return 0, err
+	}
+	perHop := hopDuration
+	if upgradeAbortAt.enabled() {
+		perHop *= 2
+	}
+	perHop += hopStartDuration + hopPoolsDuration
+	timeout := time.Duration(len(hops))*perHop + invariantsDuration
+	if upgradeEUS {
+		timeout += eusPoolsDuration
+	}
+	return timeout, nil
+}
+
 // SetTests controls the list of tests to run during an upgrade. See AllTests for the supported
 // suite.
 func SetTests(tests []upgrades.Test) {
 	upgradeTests = tests
 }
 
+// SetUpgradeChannel sets the channel the cluster is switched to before it picks an
+// update from its available updates.
+func SetUpgradeChannel(channel string) {
+	upgradeChannel = channel
+}
+
+// SetUpgradeEUS enables an EUS upgrade, which pauses the worker pools, upgrades the
+// control plane through two versions, and then unpauses the pools.
+func SetUpgradeEUS(value string) error {
+	eus, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("eus must be 'true' or 'false'")
+	}
+	upgradeEUS = eus
+	return nil
+}
+
 func SetUpgradeDisruptReboot(policy string) error {
 	switch policy {
 	case "graceful", "force":
@@ -77,27 +198,58 @@ func SetUpgradeDisruptReboot(policy string) error {
 //
 // * empty string - do not abort
 // * integer between 0-100 - once this percentage of operators have updated, rollback to the previous version
+// * operator:NAME - once the named cluster operator has updated, rollback to the previous version
+// * co:NAME:CONDITION=STATUS - once the named cluster operator reports the condition with the
+//   status, rollback to the previous version
 //
 func SetUpgradeAbortAt(policy string) error {
-	if len(policy) == 0 {
-		upgradeAbortAt = 0
-	}
-	if policy == "random" {
-		upgradeAbortAt = upgradeAbortAtRandom
-		return nil
+	trigger, err := parseAbortAt(policy)
+	if err != nil {
+		return err
 	}
-	if val, err := strconv.Atoi(policy); err == nil {
-		if val < 0 || val > 100 {
-			return fmt.Errorf("abort-at must be empty, set to 'random', or an integer in [0,100], inclusive")
+	upgradeAbortAt = trigger
+	return nil
+}
+
+const abortAtUsage = "abort-at must be empty, set to 'random', an integer in [0,100] inclusive, 'operator:NAME' or 'co:NAME:CONDITION=STATUS'"
+
+func parseAbortAt(policy string) (abortTrigger, error) {
+	switch {
+	case len(policy) == 0:
+		return abortTrigger{}, nil
+	case policy == "random":
+		return abortTrigger{percent: upgradeAbortAtRandom}, nil
+	case strings.HasPrefix(policy, "operator:"):
+		name := strings.TrimPrefix(policy, "operator:")
+		if len(name) == 0 {
+			return abortTrigger{}, fmt.Errorf("%s, the operator name is missing", abortAtUsage)
 		}
-		if val == 0 {
-			upgradeAbortAt = 1
-		} else {
-			upgradeAbortAt = val
+		return abortTrigger{operator: name}, nil
+	case strings.HasPrefix(policy, "co:"):
+		parts := strings.SplitN(strings.TrimPrefix(policy, "co:"), ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return abortTrigger{}, fmt.Errorf("%s, the operator name is missing", abortAtUsage)
 		}
-		return nil
+		condition := strings.SplitN(parts[1], "=", 2)
+		if len(condition) != 2 || len(condition[0]) == 0 {
+			return abortTrigger{}, fmt.Errorf("%s, the condition %q is not CONDITION=STATUS", abortAtUsage, parts[1])
+		}
+		status := configv1.ConditionStatus(condition[1])
+		switch status {
+		case configv1.ConditionTrue, configv1.ConditionFalse, configv1.ConditionUnknown:
+		default:
+			return abortTrigger{}, fmt.Errorf("%s, the status %q is not True, False or Unknown", abortAtUsage, condition[1])
+		}
+		return abortTrigger{operator: parts[0], condition: configv1.ClusterStatusConditionType(condition[0]), status: status}, nil
+	}
+	val, err := strconv.Atoi(policy)
+	if err != nil || val < 0 || val > 100 {
+		return abortTrigger{}, errors.New(abortAtUsage)
 	}
-	return fmt.Errorf("abort-at must be empty, set to 'random', or an integer in [0,100], inclusive")
+	if val == 0 {
+		val = 1
+	}
+	return abortTrigger{percent: val}, nil
 }
 
 func latestHistory(history []configv1.UpdateHistory) *configv1.UpdateHistory {
@@ -171,76 +323,269 @@ func getUpgradeContext(c configv1client.Interface, upgradeTarget, upgradeImage s
 		},
 	}
 
-	if len(upgradeTarget) == 0 && len(upgradeImage) == 0 {
+	hops, err := upgradeHops(upgradeTarget, upgradeImage, upgradeChannel)
+	if err != nil {
+		return nil, err
+	}
+	if len(hops) == 0 {
 		return upgCtx, nil
 	}
 
-	if (len(upgradeImage) > 0 && upgradeImage == current.Image) || (len(upgradeTarget) > 0 && upgradeTarget == current.Version) {
+	if first := hops[0]; (len(first.NodeImage) > 0 && first.NodeImage == current.Image) || (first.Version.String() == current.Version) {
 		return nil, fmt.Errorf("cluster is already at version %s", versionString(*current))
 	}
+	upgCtx.Versions = append(upgCtx.Versions, hops...)
+
+	return upgCtx, nil
+}
 
-	var next upgrades.VersionContext
-	next.NodeImage = upgradeImage
+// upgradeHops returns a version to upgrade to for each of the comma-separated
+// targets or images, in order. A hop without an image is resolved from the
+// available updates of the cluster when it starts, and a channel without targets
+// or images is a single hop to the latest update available in it.
+func upgradeHops(upgradeTarget, upgradeImage, channel string) ([]upgrades.VersionContext, error) {
+	var targets, images []string
 	if len(upgradeTarget) > 0 {
-		nextVer, err := version.ParseSemantic(upgradeTarget)
-		if err != nil {
+		targets = strings.Split(upgradeTarget, ",")
+	}
+	if len(upgradeImage) > 0 {
+		images = strings.Split(upgradeImage, ",")
+	}
+	if len(targets) > 0 && len(images) > 0 && len(targets) != len(images) {
+		return nil, fmt.Errorf("%d upgrade versions were given for %d upgrade images, each image must have a version", len(targets), len(images))
+	}
+
+	hops := make([]upgrades.VersionContext, len(targets))
+	if len(images) > len(targets) {
+		hops = make([]upgrades.VersionContext, len(images))
+	}
+	if len(hops) == 0 && len(channel) > 0 {
+		return []upgrades.VersionContext{{}}, nil
+	}
+	for i := range hops {
+		if len(images) > 0 {
+			if len(images[i]) == 0 {
+				return nil, fmt.Errorf("upgrade image %d of %q is empty", i+1, upgradeImage)
+			}
+			hops[i].NodeImage = images[i]
+		}
+		if len(targets) > 0 {
+			nextVer, err := version.ParseSemantic(targets[i])
+			if err != nil {
+				return nil, err
+			}
+			hops[i].Version = *nextVer
+		}
+	}
+	return hops, nil
+}
+
+// hopSteps returns the step that upgrades the cluster with upgrade and, if abort-at
+// is set, a step that reports the outcome of the rollback as its own test case.
+func hopSteps(name, target string, upgrade func() (rolledBack bool, err error)) []disruption.Step {
+	if !upgradeAbortAt.enabled() {
+		return []disruption.Step{{
+			Name: name,
+			Run: func() {
+				_, err := upgrade()
+				framework.ExpectNoError(err, fmt.Sprintf("during upgrade to %s", target))
+			},
+		}}
+	}
+
+	rollbackName := "rollback"
+	if len(name) > 0 {
+		rollbackName = fmt.Sprintf("%s rollback", name)
+	}
+	var rolledBack bool
+	var rollbackErr error
+	return []disruption.Step{
+		{
+			Name: name,
+			Run: func() {
+				var err error
+				rolledBack, err = upgrade()
+				if rolledBack {
+					rollbackErr = err
+					return
+				}
+				framework.ExpectNoError(err, fmt.Sprintf("during upgrade to %s", target))
+			},
+		},
+		{
+			Name: rollbackName,
+			Run: func() {
+				if !rolledBack {
+					framework.Failf("The upgrade to %s completed before %s, so it was not rolled back", target, upgradeAbortAt)
+				}
+				framework.ExpectNoError(rollbackErr, fmt.Sprintf("during rollback of the upgrade to %s", target))
+			},
+		},
+	}
+}
+
+// hopString describes the version or image a hop upgrades to.
+func hopString(hop upgrades.VersionContext) string {
+	switch {
+	case len(hop.NodeImage) > 0 && hop.Version.String() != "":
+		return fmt.Sprintf("%s (%s)", hop.Version.String(), hop.NodeImage)
+	case len(hop.NodeImage) > 0:
+		return hop.NodeImage
+	case hop.Version.String() != "":
+		return hop.Version.String()
+	default:
+		return fmt.Sprintf("the latest update in channel %s", upgradeChannel)
+	}
+}
+
+// resolveUpdate switches the cluster to the upgrade channel, if one is set, and
+// returns the update to target from the available updates of the cluster, or the
+// latest available update if target is empty.
+func resolveUpdate(c configv1client.Interface, target string) (*configv1.Update, error) {
+	if len(upgradeChannel) > 0 {
+		if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			cv, err := c.ConfigV1().ClusterVersions().Get("version", metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if cv.Spec.Channel == upgradeChannel {
+				return nil
+			}
+			framework.Logf("Switching the cluster from channel %q to %q", cv.Spec.Channel, upgradeChannel)
+			cv.Spec.Channel = upgradeChannel
+			_, err = c.ConfigV1().ClusterVersions().Update(cv)
+			return err
+		}); err != nil {
 			return nil, err
 		}
-		next.Version = *nextVer
 	}
-	upgCtx.Versions = append(upgCtx.Versions, next)
 
-	return upgCtx, nil
+	var available []configv1.Update
+	var lastMessage string
+	if err := wait.PollImmediate(5*time.Second, 5*time.Minute, func() (bool, error) {
+		cv, err := c.ConfigV1().ClusterVersions().Get("version", metav1.GetOptions{})
+		if err != nil {
+			framework.Logf("error getting cluster version: %v", err)
+			return false, nil
+		}
+		if cv.Status.ObservedGeneration < cv.Generation {
+			return false, nil
+		}
+		retrieved := findCondition(cv.Status.Conditions, configv1.RetrievedUpdates)
+		if retrieved == nil || retrieved.Status != configv1.ConditionTrue {
+			if retrieved != nil {
+				lastMessage = retrieved.Message
+			}
+			return false, nil
+		}
+		available = cv.Status.AvailableUpdates
+		return true, nil
+	}); err != nil {
+		if lastMessage != "" {
+			return nil, fmt.Errorf("cluster did not retrieve the available updates: %v: %s", err, lastMessage)
+		}
+		return nil, fmt.Errorf("cluster did not retrieve the available updates: %v", err)
+	}
+	return pickUpdate(available, target)
+}
+
+// pickUpdate returns the available update to target, or the available update with
+// the highest version if target is empty.
+func pickUpdate(available []configv1.Update, target string) (*configv1.Update, error) {
+	var versions []string
+	var latest *configv1.Update
+	var latestVer *version.Version
+	for i := range available {
+		update := &available[i]
+		versions = append(versions, update.Version)
+		if len(target) > 0 {
+			if update.Version == target {
+				return update, nil
+			}
+			continue
+		}
+		v, err := version.ParseSemantic(update.Version)
+		if err != nil {
+			framework.Logf("ignoring available update with an invalid version %q: %v", update.Version, err)
+			continue
+		}
+		if latestVer == nil || latestVer.LessThan(v) {
+			latest, latestVer = update, v
+		}
+	}
+	switch {
+	case len(available) == 0:
+		return nil, fmt.Errorf("the cluster has no available updates")
+	case len(target) > 0:
+		return nil, fmt.Errorf("version %s is not an available update, available updates are: %s", target, strings.Join(versions, ", "))
+	case latest == nil:
+		return nil, fmt.Errorf("none of the available updates has a valid version: %s", strings.Join(versions, ", "))
+	}
+	return latest, nil
 }
 
 var errControlledAbort = fmt.Errorf("beginning abort")
 
-func clusterUpgrade(c configv1client.Interface, dc dynamic.Interface, config *rest.Config, version upgrades.VersionContext) error {
+// clusterUpgrade upgrades the cluster to version. If abort-at is reached the
+// upgrade is rolled back to the previous version instead, rolledBack is true, and
+// err is the outcome of the rollback.
+func clusterUpgrade(c configv1client.Interface, dc dynamic.Interface, config *rest.Config, version upgrades.VersionContext) (rolledBack bool, err error) {
 	fmt.Fprintf(os.Stderr, "\n\n\n")
 	defer func() { fmt.Fprintf(os.Stderr, "\n\n\n") }()
 
 	if version.NodeImage == "[pause]" {
 		framework.Logf("Running a dry-run upgrade test")
 		time.Sleep(2 * time.Minute)
-		return nil
+		return false, nil
 	}
 
 	kubeClient := kubernetes.NewForConfigOrDie(config)
 
-	maximumDuration := 75 * time.Minute
+	maximumDuration := hopDuration
 
-	framework.Logf("Starting upgrade to version=%s image=%s", version.Version.String(), version.NodeImage)
+	desired := configv1.Update{
+		Version: version.Version.String(),
+		Image:   version.NodeImage,
+		Force:   true,
+	}
+	if len(version.NodeImage) == 0 {
+		// pick the update from the available updates the way a customer would, which
+		// the cluster verifies, so the update is not forced
+		update, err := resolveUpdate(c, version.Version.String())
+		if err != nil {
+			return false, err
+		}
+		desired = *update
+		desired.Force = false
+	}
+
+	framework.Logf("Starting upgrade to version=%s image=%s", desired.Version, desired.Image)
 
-	// decide whether to abort at a percent
+	// decide whether to abort
 	abortAt := upgradeAbortAt
-	switch abortAt {
-	case 0:
+	switch {
+	case !abortAt.enabled():
 		// no abort
-	case upgradeAbortAtRandom:
-		abortAt = int(rand.Int31n(100) + 1)
+	case abortAt.percent == upgradeAbortAtRandom:
+		abortAt.percent = int(rand.Int31n(100) + 1)
 		maximumDuration *= 2
-		framework.Logf("Upgrade will be aborted and the cluster will roll back to the current version after %d%% of operators have upgraded (picked randomly)", abortAt)
+		framework.Logf("Upgrade will be aborted and the cluster will roll back to the current version after %s (picked randomly)", abortAt)
 	default:
 		maximumDuration *= 2
-		framework.Logf("Upgrade will be aborted and the cluster will roll back to the current version after %d%% of operators have upgraded", upgradeAbortAt)
+		framework.Logf("Upgrade will be aborted and the cluster will roll back to the current version after %s", abortAt)
 	}
 
 	// trigger the update
 	cv, err := c.ConfigV1().ClusterVersions().Get("version", metav1.GetOptions{})
 	if err != nil {
-		return err
+		return false, err
 	}
 	oldImage := cv.Status.Desired.Image
 	oldVersion := cv.Status.Desired.Version
-	desired := configv1.Update{
-		Version: version.Version.String(),
-		Image:   version.NodeImage,
-		Force:   true,
-	}
 	cv.Spec.DesiredUpdate = &desired
 	updated, err := c.ConfigV1().ClusterVersions().Update(cv)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	monitor := versionMonitor{
@@ -258,7 +603,7 @@ func clusterUpgrade(c configv1client.Interface, dc dynamic.Interface, config *re
 
 	}); err != nil {
 		monitor.Output()
-		return fmt.Errorf("Cluster did not acknowledge request to upgrade in a reasonable time: %v", err)
+		return false, fmt.Errorf("Cluster did not acknowledge request to upgrade in a reasonable time: %v", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -306,21 +651,38 @@ func clusterUpgrade(c configv1client.Interface, dc dynamic.Interface, config *re
 
 	}); err != nil {
 		monitor.Output()
+		action := "upgrade"
+		if aborted {
+			action = "rollback"
+		}
 		if lastMessage != "" {
-			return fmt.Errorf("Cluster did not complete upgrade: %v: %s", err, lastMessage)
+			return aborted, fmt.Errorf("Cluster did not complete %s: %v: %s", action, err, lastMessage)
 		}
-		return fmt.Errorf("Cluster did not complete upgrade: %v", err)
+		return aborted, fmt.Errorf("Cluster did not complete %s: %v", action, err)
 	}
 
 	framework.Logf("Completed upgrade to %s", versionString(desired))
 
 	framework.Logf("Waiting on pools to be upgraded")
-	if err := wait.PollImmediate(10*time.Second, 30*time.Minute, func() (bool, error) {
-		mcps := dc.Resource(schema.GroupVersionResource{
-			Group:    "machineconfiguration.openshift.io",
-			Version:  "v1",
-			Resource: "machineconfigpools",
-		})
+	if err := waitForPools(dc, hopPoolsDuration); err != nil {
+		return aborted, err
+	}
+	framework.Logf("All pools completed upgrade")
+
+	return aborted, nil
+}
+
+var machineConfigPools = schema.GroupVersionResource{
+	Group:    "machineconfiguration.openshift.io",
+	Version:  "v1",
+	Resource: "machineconfigpools",
+}
+
+// waitForPools waits until every pool that is not paused is updated. Paused pools
+// do not roll out the new configuration until they are unpaused.
+func waitForPools(dc dynamic.Interface, timeout time.Duration) error {
+	mcps := dc.Resource(machineConfigPools)
+	if err := wait.PollImmediate(10*time.Second, timeout, func() (bool, error) {
 		pools, err := mcps.List(metav1.ListOptions{})
 		if err != nil {
 			framework.Logf("error getting pools %v", err)
@@ -328,6 +690,10 @@ func clusterUpgrade(c configv1client.Interface, dc dynamic.Interface, config *re
 		}
 		allUpdated := true
 		for _, p := range pools.Items {
+			if paused, _, _ := unstructured.NestedBool(p.Object, "spec", "paused"); paused {
+				framework.Logf("Pool %s is paused and will be updated when it is unpaused", p.GetName())
+				continue
+			}
 			updated, err := IsPoolUpdated(mcps, p.GetName())
 			if err != nil {
 				framework.Logf("error checking pool %s: %v", p.GetName(), err)
@@ -339,11 +705,89 @@ func clusterUpgrade(c configv1client.Interface, dc dynamic.Interface, config *re
 	}); err != nil {
 		return fmt.Errorf("Pools did not complete upgrade: %v", err)
 	}
-	framework.Logf("All pools completed upgrade")
+	return nil
+}
+
+// workerPools pauses every pool but the master pool, as a customer does to upgrade
+// the control plane of an EUS release twice while the workers are only rebooted
+// once, and unpauses only the pools it paused.
+type workerPools struct {
+	dc dynamic.Interface
+	// paused are the pools paused by pause that were not unpaused yet
+	paused []string
+}
 
+// pause pauses the pools that are not paused yet.
+func (p *workerPools) pause() error {
+	mcps := p.dc.Resource(machineConfigPools)
+	pools, err := mcps.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, pool := range pools.Items {
+		if pool.GetName() == "master" {
+			continue
+		}
+		if paused, _, _ := unstructured.NestedBool(pool.Object, "spec", "paused"); paused {
+			continue
+		}
+		if err := setPoolPaused(mcps, pool.GetName(), true); err != nil {
+			return err
+		}
+		p.paused = append(p.paused, pool.GetName())
+	}
 	return nil
 }
 
+// unpause unpauses the pools paused by pause and returns their names. Pools that
+// were paused before are left paused.
+func (p *workerPools) unpause() ([]string, error) {
+	mcps := p.dc.Resource(machineConfigPools)
+	var unpaused []string
+	for len(p.paused) > 0 {
+		if err := setPoolPaused(mcps, p.paused[0], false); err != nil {
+			return unpaused, err
+		}
+		unpaused = append(unpaused, p.paused[0])
+		p.paused = p.paused[1:]
+	}
+	return unpaused, nil
+}
+
+func setPoolPaused(mcps dynamic.NamespaceableResourceInterface, name string, paused bool) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, paused))
+	if _, err := mcps.Patch(name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("unable to set paused=%t on pool %s: %v", paused, name, err)
+	}
+	return nil
+}
+
+// eusSteps wraps the two control-plane hops of an EUS upgrade with pausing the
+// worker pools before them and unpausing the pools and waiting for them after.
+func eusSteps(pools *workerPools, hops []disruption.Step) ([]disruption.Step, error) {
+	if len(hops) != 2 {
+		return nil, fmt.Errorf("an EUS upgrade upgrades the control plane twice, but %d upgrades were requested", len(hops))
+	}
+	steps := []disruption.Step{{
+		Name: "pause worker pools",
+		Run: func() {
+			framework.ExpectNoError(pools.pause(), "pausing worker pools")
+			framework.Logf("Paused pools %v", pools.paused)
+		},
+	}}
+	steps = append(steps, hops...)
+	steps = append(steps, disruption.Step{
+		Name: "unpause worker pools",
+		Run: func() {
+			unpaused, err := pools.unpause()
+			framework.ExpectNoError(err, "unpausing worker pools")
+			framework.Logf("Unpaused pools %v", unpaused)
+			framework.ExpectNoError(waitForPools(pools.dc, eusPoolsDuration), "waiting for pools after unpausing them")
+		},
+	})
+	return steps, nil
+}
+
 // TODO(runcom): drop this when MCO types are in openshift/api and we can use the typed client directly
 func IsPoolUpdated(dc dynamic.NamespaceableResourceInterface, name string) (bool, error) {
 	pool, err := dc.Get(name, metav1.GetOptions{})
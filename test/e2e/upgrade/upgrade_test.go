@@ -0,0 +1,251 @@
+package upgrade
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/openshift-tests-private/test/extended/util/disruption"
+)
+
+func TestUpgradeHops(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		image    string
+		channel  string
+		versions []string
+		images   []string
+		err      string
+	}{
+		{name: "none"},
+		{name: "channel only", channel: "stable-4.7", versions: []string{""}, images: []string{""}},
+		{name: "single image", image: "quay.io/a", versions: []string{""}, images: []string{"quay.io/a"}},
+		{
+			name:     "image chain",
+			image:    "quay.io/a,quay.io/b,quay.io/c",
+			versions: []string{"", "", ""},
+			images:   []string{"quay.io/a", "quay.io/b", "quay.io/c"},
+		},
+		{
+			name:     "version chain",
+			target:   "4.6.9,4.7.0",
+			channel:  "stable-4.7",
+			versions: []string{"4.6.9", "4.7.0"},
+			images:   []string{"", ""},
+		},
+		{
+			name:     "versions of images",
+			target:   "4.6.9,4.7.0",
+			image:    "quay.io/a,quay.io/b",
+			versions: []string{"4.6.9", "4.7.0"},
+			images:   []string{"quay.io/a", "quay.io/b"},
+		},
+		{name: "mismatched", target: "4.6.9", image: "quay.io/a,quay.io/b", err: "2 upgrade images"},
+		{name: "empty image", image: "quay.io/a,", err: "upgrade image 2"},
+		{name: "invalid version", target: "4.7", err: "4.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hops, err := upgradeHops(tt.target, tt.image, tt.channel)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hops) != len(tt.versions) {
+				t.Fatalf("expected %d hops, got %#v", len(tt.versions), hops)
+			}
+			for i, hop := range hops {
+				if hop.Version.String() != tt.versions[i] || hop.NodeImage != tt.images[i] {
+					t.Errorf("hop %d: expected %q/%q, got %q/%q", i, tt.versions[i], tt.images[i], hop.Version.String(), hop.NodeImage)
+				}
+			}
+		})
+	}
+}
+
+func TestTestTimeout(t *testing.T) {
+	defer func() { upgradeAbortAt, upgradeEUS = abortTrigger{}, false }()
+	tests := []struct {
+		name    string
+		image   string
+		abortAt abortTrigger
+		eus     bool
+		timeout time.Duration
+	}{
+		{name: "one hop", image: "quay.io/a", timeout: 145 * time.Minute},
+		{name: "two hops", image: "quay.io/a,quay.io/b", timeout: 260 * time.Minute},
+		{name: "two hops rolled back", image: "quay.io/a,quay.io/b", abortAt: abortTrigger{percent: 50}, timeout: 410 * time.Minute},
+		{name: "eus", image: "quay.io/a,quay.io/b", eus: true, timeout: 320 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgradeAbortAt, upgradeEUS = tt.abortAt, tt.eus
+			timeout, err := TestTimeout("", tt.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if timeout != tt.timeout {
+				t.Errorf("expected %s, got %s", tt.timeout, timeout)
+			}
+		})
+	}
+}
+
+func TestPickUpdate(t *testing.T) {
+	available := []configv1.Update{
+		{Version: "4.6.12", Image: "quay.io/4.6.12"},
+		{Version: "4.7.0", Image: "quay.io/4.7.0"},
+		{Version: "4.6.9", Image: "quay.io/4.6.9"},
+	}
+	tests := []struct {
+		name      string
+		available []configv1.Update
+		target    string
+		image     string
+		err       string
+	}{
+		{name: "latest", available: available, image: "quay.io/4.7.0"},
+		{name: "target", available: available, target: "4.6.12", image: "quay.io/4.6.12"},
+		{name: "unavailable target", available: available, target: "4.6.10", err: "available updates are: 4.6.12, 4.7.0, 4.6.9"},
+		{name: "no updates", err: "no available updates"},
+		{name: "invalid versions", available: []configv1.Update{{Version: "latest"}}, err: "valid version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, err := pickUpdate(tt.available, tt.target)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if update.Image != tt.image {
+				t.Errorf("expected %s, got %#v", tt.image, update)
+			}
+		})
+	}
+}
+
+func TestWorkerPools(t *testing.T) {
+	pool := func(name string, paused bool) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "machineconfiguration.openshift.io/v1",
+			"kind":       "MachineConfigPool",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"paused": paused},
+		}}
+	}
+	// the infra pool was paused by the administrator before the upgrade
+	dc := fake.NewSimpleDynamicClient(runtime.NewScheme(), pool("master", false), pool("worker", false), pool("infra", true))
+	expectPaused := func(expected map[string]bool) {
+		t.Helper()
+		for name, expected := range expected {
+			p, err := dc.Resource(machineConfigPools).Get(name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual, _, _ := unstructured.NestedBool(p.Object, "spec", "paused"); actual != expected {
+				t.Errorf("pool %s: expected paused=%t, got %t", name, expected, actual)
+			}
+		}
+	}
+
+	pools := &workerPools{dc: dc}
+	if err := pools.pause(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pools.paused, []string{"worker"}) {
+		t.Errorf("expected only the worker pool to be paused, got %v", pools.paused)
+	}
+	expectPaused(map[string]bool{"master": false, "worker": true, "infra": true})
+
+	unpaused, err := pools.unpause()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unpaused, []string{"worker"}) {
+		t.Errorf("expected only the worker pool to be unpaused, got %v", unpaused)
+	}
+	expectPaused(map[string]bool{"master": false, "worker": false, "infra": true})
+
+	if unpaused, err := pools.unpause(); err != nil || len(unpaused) != 0 {
+		t.Errorf("expected unpausing again to do nothing, got %v %v", unpaused, err)
+	}
+}
+
+func TestEUSSteps(t *testing.T) {
+	hop := disruption.Step{Name: "hop", Run: func() {}}
+	if _, err := eusSteps(&workerPools{}, []disruption.Step{hop}); err == nil || !strings.Contains(err.Error(), "1 upgrades") {
+		t.Fatalf("expected an error for a single hop, got %v", err)
+	}
+	steps, err := eusSteps(&workerPools{}, []disruption.Step{hop, hop})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, step := range steps {
+		names = append(names, step.Name)
+	}
+	if expected := []string{"pause worker pools", "hop", "hop", "unpause worker pools"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected steps %v, got %v", expected, names)
+	}
+}
+
+func TestParseAbortAt(t *testing.T) {
+	tests := []struct {
+		policy  string
+		trigger abortTrigger
+		err     bool
+	}{
+		{policy: ""},
+		{policy: "random", trigger: abortTrigger{percent: upgradeAbortAtRandom}},
+		{policy: "0", trigger: abortTrigger{percent: 1}},
+		{policy: "50", trigger: abortTrigger{percent: 50}},
+		{policy: "101", err: true},
+		{policy: "operator:machine-config", trigger: abortTrigger{operator: "machine-config"}},
+		{policy: "operator:", err: true},
+		{
+			policy:  "co:network:Progressing=True",
+			trigger: abortTrigger{operator: "network", condition: configv1.OperatorProgressing, status: configv1.ConditionTrue},
+		},
+		{policy: "co::Progressing=True", err: true},
+		{policy: "co:network", err: true},
+		{policy: "co:network:Progressing", err: true},
+		{policy: "co:network:Progressing=true", err: true},
+		{policy: "machine-config", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			trigger, err := parseAbortAt(tt.policy)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", trigger)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trigger != tt.trigger {
+				t.Errorf("expected %#v, got %#v", tt.trigger, trigger)
+			}
+		})
+	}
+}
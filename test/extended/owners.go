@@ -0,0 +1,11 @@
+package extended
+
+import "embed"
+
+// OwnersFiles holds the OWNERS files of the test directories, so that the owners
+// of a test can be resolved without the source tree. OWNERS files added below the
+// first level of directories must be added to the pattern, TestOwnersFiles fails
+// until they are.
+//
+//go:embed OWNERS */OWNERS
+var OwnersFiles embed.FS
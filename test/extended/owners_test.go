@@ -0,0 +1,28 @@
+package extended
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOwnersFiles fails if an OWNERS file of the test directories is not embedded,
+// as the pattern of OwnersFiles only matches the first level of directories.
+func TestOwnersFiles(t *testing.T) {
+	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != "OWNERS" {
+			return nil
+		}
+		if _, err := fs.Stat(OwnersFiles, filepath.ToSlash(path)); err != nil {
+			t.Errorf("%s is not embedded, add it to the pattern of OwnersFiles: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
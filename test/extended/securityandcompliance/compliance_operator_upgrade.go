@@ -99,7 +99,7 @@ var _ = g.Describe("[sig-isc] Security_and_Compliance Pre-check and post-check f
 		})
 
 		// author: xiyuan@redhat.com
-		g.It("Author:xiyuan-CPaasrunOnly-NonPreRelease-High-37721-High-37824-High-45014-precheck for compliance operator", func() {
+		g.It("Author:xiyuan-CPaasrunOnly-NonPreRelease-High-37721-High-37824-High-45014-precheck for compliance operator [PreChkUpgrade]", func() {
 			g.By("Create scansettingbinding !!!\n")
 			ssb.namespace = ns1
 			ssb2.namespace = ns2
@@ -130,7 +130,7 @@ var _ = g.Describe("[sig-isc] Security_and_Compliance Pre-check and post-check f
 		})
 
 		// author: pdhamdhe@redhat.com
-		g.It("Author:pdhamdhe-CPaasrunOnly-NonPreRelease-High-45014-High-45956-precheck for compliance operator resources count and MachineConfigPool status", func() {
+		g.It("Author:pdhamdhe-CPaasrunOnly-NonPreRelease-High-45014-High-45956-precheck for compliance operator resources count and MachineConfigPool status [PreChkUpgrade]", func() {
 			g.By("Check the MachineConfigPool status after upgrade.. !!\n")
 			newCheck("expect", asAdmin, withoutNamespace, contain, "false", ok, []string{"machineconfigpool", "master", "-n", ns1,
 				"-ojsonpath={.spec.paused}"}).check(oc)
@@ -155,7 +155,7 @@ var _ = g.Describe("[sig-isc] Security_and_Compliance Pre-check and post-check f
 		})
 
 		// author: xiyuan@redhat.com
-		g.It("Author:xiyuan-CPaasrunOnly-NonPreRelease-High-37721-High-37824-postcheck for compliance operator", func() {
+		g.It("Author:xiyuan-CPaasrunOnly-NonPreRelease-High-37721-High-37824-postcheck for compliance operator [PstChkUpgrade]", func() {
 			defer cleanupObjects(oc,
 				objectTableRef{"scansettingbinding", ns1, ssb.name},
 				objectTableRef{"scansettingbinding", ns2, ssb2.name},
@@ -188,7 +188,7 @@ var _ = g.Describe("[sig-isc] Security_and_Compliance Pre-check and post-check f
 		})
 
 		// author: pdhamdhe@redhat.com
-		g.It("Author:pdhamdhe-CPaasrunOnly-NonPreRelease-High-45014-High-45956-postcheck for compliance operator resources count and MachineConfigPool status", func() {
+		g.It("Author:pdhamdhe-CPaasrunOnly-NonPreRelease-High-45014-High-45956-postcheck for compliance operator resources count and MachineConfigPool status [PstChkUpgrade]", func() {
 			confmap.namespace = ns2
 			defer cleanupObjects(oc, objectTableRef{"configmap", confmap.namespace, confmap.name})
 			g.By("Check the MachineConfigPool status after upgrade.. !!\n")
@@ -59,7 +59,7 @@ var _ = g.Describe("[sig-isc] Security_and_Compliance Pre-check and post-check f
 		})
 
 		// author: pdhamdhe@redhat.com
-		g.It("Author:pdhamdhe-NonPreRelease-CPaasrunOnly-High-39254-Critical-42663-Critical-45366-precheck for file integrity operator", func() {
+		g.It("Author:pdhamdhe-NonPreRelease-CPaasrunOnly-High-39254-Critical-42663-Critical-45366-precheck for file integrity operator [PreChkUpgrade]", func() {
 			g.By("Create file integrity object  !!!\n")
 			fi1.namespace = ns1
 			err := applyResourceFromTemplate(oc, "--ignore-unknown-parameters=true", "-f", fi1.template, "-p", "NAME="+fi1.name, "NAMESPACE="+fi1.namespace, "GRACEPERIOD="+strconv.Itoa(fi1.graceperiod),
@@ -83,10 +83,16 @@ var _ = g.Describe("[sig-isc] Security_and_Compliance Pre-check and post-check f
 			for _, v := range fionodeName {
 				fi1.checkFileintegritynodestatus(oc, v, "Succeeded")
 			}
+
+			if exutil.UpgradeStateEnabled() {
+				g.By("Record the nodes checked before the upgrade.. !!!\n")
+				err = exutil.SetUpgradeState("fileintegrity-nodes", fionodeNames)
+				o.Expect(err).NotTo(o.HaveOccurred())
+			}
 		})
 
 		// author: pdhamdhe@redhat.com
-		g.It("Author:pdhamdhe-NonPreRelease-CPaasrunOnly-High-39254-Critical-42663-Critical-45366-postcheck for file integrity operator", func() {
+		g.It("Author:pdhamdhe-NonPreRelease-CPaasrunOnly-High-39254-Critical-42663-Critical-45366-postcheck for file integrity operator [PstChkUpgrade]", func() {
 			fi1.namespace = ns1
 			defer cleanupObjects(oc,
 				objectTableRef{"fileintegrity", ns2, fi1.name},
@@ -108,6 +114,14 @@ var _ = g.Describe("[sig-isc] Security_and_Compliance Pre-check and post-check f
 			for _, v := range fionodeName {
 				fi1.checkFileintegritynodestatus(oc, v, "Succeeded")
 			}
+			if exutil.UpgradeStateEnabled() {
+				g.By("Check the nodes checked before the upgrade still report their status.. !!!\n")
+				checkedNodeNames, err := exutil.GetUpgradeState("fileintegrity-nodes")
+				o.Expect(err).NotTo(o.HaveOccurred())
+				for _, v := range strings.Fields(checkedNodeNames) {
+					fi1.checkFileintegritynodestatus(oc, v, "Succeeded")
+				}
+			}
 
 			g.By("Delete file integrity object ns1 !!!\n")
 			cleanupObjects(oc, objectTableRef{"fileintegrity", ns1, fi1.name})
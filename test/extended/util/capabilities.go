@@ -0,0 +1,162 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// clusterCapabilities describes the properties of a cluster that decide whether a
+// test can run on it. Empty fields are unknown and do not exclude any test.
+type clusterCapabilities struct {
+	// IPStack is "ipv4", "ipv6" or "dual"
+	IPStack string `json:"ipStack,omitempty"`
+	// Topology is "SingleReplica" for single-node clusters or "HighlyAvailable"
+	Topology      string   `json:"topology,omitempty"`
+	Architectures []string `json:"architectures,omitempty"`
+	Proxy         bool     `json:"proxy,omitempty"`
+	Disconnected  bool     `json:"disconnected,omitempty"`
+	FIPS          bool     `json:"fips,omitempty"`
+	// FeatureSet is the feature set of the cluster, "Default" if none is set
+	FeatureSet string `json:"featureSet,omitempty"`
+	// DisabledCapabilities are the optional capabilities the cluster knows of but
+	// does not enable
+	DisabledCapabilities []string `json:"disabledCapabilities,omitempty"`
+}
+
+// getClusterCapabilities detects the capabilities of the cluster of KUBECONFIG.
+func getClusterCapabilities() *clusterCapabilities {
+	return discoverClusterCapabilities(func(args ...string) (string, error) {
+		out, err := e2e.KubectlCmd(args...).Output()
+		return strings.TrimSpace(string(out)), err
+	})
+}
+
+// discoverClusterCapabilities detects the capabilities of the cluster with kubectl.
+// A cluster is considered disconnected if it was installed with mirrored release
+// images.
+func discoverClusterCapabilities(kubectl func(args ...string) (string, error)) *clusterCapabilities {
+	c := &clusterCapabilities{}
+	get := func(args ...string) string {
+		out, err := kubectl(append([]string{"get"}, args...)...)
+		if err != nil {
+			e2e.Logf("Could not detect cluster capabilities with %v: %v", args, err)
+			return ""
+		}
+		return out
+	}
+
+	var v4, v6 bool
+	for _, cidr := range strings.Fields(get("network.config.openshift.io", "cluster", "-o", "jsonpath={.status.clusterNetwork[*].cidr}")) {
+		if strings.Contains(cidr, ":") {
+			v6 = true
+		} else {
+			v4 = true
+		}
+	}
+	switch {
+	case v4 && v6:
+		c.IPStack = "dual"
+	case v6:
+		c.IPStack = "ipv6"
+	case v4:
+		c.IPStack = "ipv4"
+	}
+
+	c.Topology = get("infrastructure.config.openshift.io", "cluster", "-o", "jsonpath={.status.controlPlaneTopology}")
+
+	architectures := make(map[string]struct{})
+	for _, arch := range strings.Fields(get("nodes", "-o", "jsonpath={.items[*].status.nodeInfo.architecture}")) {
+		architectures[arch] = struct{}{}
+	}
+	for arch := range architectures {
+		c.Architectures = append(c.Architectures, arch)
+	}
+	sort.Strings(c.Architectures)
+
+	c.Proxy = len(get("proxy.config.openshift.io", "cluster", "-o", "jsonpath={.status.httpProxy}{.status.httpsProxy}")) > 0
+
+	var installConfig struct {
+		FIPS                bool          `json:"fips"`
+		ImageContentSources []interface{} `json:"imageContentSources"`
+	}
+	if data := get("configmap", "cluster-config-v1", "-n", "kube-system", "-o", "jsonpath={.data.install-config}"); len(data) > 0 {
+		if err := yaml.Unmarshal([]byte(data), &installConfig); err != nil {
+			e2e.Logf("Could not read the install config: %v", err)
+		}
+	}
+	c.FIPS = installConfig.FIPS
+	c.Disconnected = len(installConfig.ImageContentSources) > 0
+
+	if featureSet, err := kubectl("get", "featuregate.config.openshift.io", "cluster", "-o", "jsonpath={.spec.featureSet}"); err == nil {
+		c.FeatureSet = featureSet
+		if len(featureSet) == 0 {
+			c.FeatureSet = "Default"
+		}
+	}
+
+	enabled := make(map[string]struct{})
+	for _, capability := range strings.Fields(get("clusterversion", "version", "-o", "jsonpath={.status.capabilities.enabledCapabilities[*]}")) {
+		enabled[capability] = struct{}{}
+	}
+	for _, capability := range strings.Fields(get("clusterversion", "version", "-o", "jsonpath={.status.capabilities.knownCapabilities[*]}")) {
+		if _, ok := enabled[capability]; !ok {
+			c.DisabledCapabilities = append(c.DisabledCapabilities, capability)
+		}
+	}
+	sort.Strings(c.DisabledCapabilities)
+	return c
+}
+
+// excludes returns the patterns of tests that cannot run on the cluster:
+//
+//	[Skipped:ipv4], [Skipped:ipv6] or [Skipped:DualStack], and [Skipped:SingleStack]
+//	[Skipped:SNO] on single-node clusters, [Skipped:HA] otherwise
+//	[Skipped:<arch>] for the architecture of every node
+//	[Skipped:Proxy], [Skipped:Disconnected] and ConnectedOnly- titles, [Skipped:FIPS]
+//	[Skipped:TechPreview] with the TechPreviewNoUpgrade feature set, [Skipped:NoTechPreview] without
+//	[Skipped:NoCapability/<name>] for every disabled optional capability
+func (c *clusterCapabilities) excludes() []string {
+	var excludes []string
+	switch c.IPStack {
+	case "ipv4":
+		excludes = append(excludes, `\[Skipped:ipv4\]`, `\[Skipped:SingleStack\]`)
+	case "ipv6":
+		excludes = append(excludes, `\[Skipped:ipv6\]`, `\[Skipped:SingleStack\]`)
+	case "dual":
+		excludes = append(excludes, `\[Skipped:DualStack\]`)
+	}
+	switch c.Topology {
+	case "SingleReplica":
+		excludes = append(excludes, `\[Skipped:SNO\]`)
+	case "HighlyAvailable":
+		excludes = append(excludes, `\[Skipped:HA\]`)
+	}
+	for _, arch := range c.Architectures {
+		excludes = append(excludes, fmt.Sprintf(`\[Skipped:%s\]`, arch))
+	}
+	if c.Proxy {
+		excludes = append(excludes, `\[Skipped:Proxy\]`)
+	}
+	if c.Disconnected {
+		excludes = append(excludes, `\[Skipped:Disconnected\]`, `\bConnectedOnly-`)
+	}
+	if c.FIPS {
+		excludes = append(excludes, `\[Skipped:FIPS\]`)
+	}
+	switch c.FeatureSet {
+	case "":
+	case "TechPreviewNoUpgrade":
+		excludes = append(excludes, `\[Skipped:TechPreview\]`)
+	default:
+		excludes = append(excludes, `\[Skipped:NoTechPreview\]`)
+	}
+	for _, capability := range c.DisabledCapabilities {
+		excludes = append(excludes, fmt.Sprintf(`\[Skipped:NoCapability/%s\]`, capability))
+	}
+	return excludes
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverClusterCapabilities(t *testing.T) {
+	responses := map[string]string{
+		"network.config.openshift.io":        "10.128.0.0/14 fd01::/48",
+		"infrastructure.config.openshift.io": "SingleReplica",
+		"nodes":                              "amd64 arm64 amd64",
+		"proxy.config.openshift.io":          "http://proxy.example.com:3128",
+		"configmap":                          "fips: true\nimageContentSources:\n- mirrors: [mirror.example.com/ocp]\n  source: quay.io/openshift-release-dev/ocp-release\n",
+		"featuregate.config.openshift.io":    "",
+	}
+	kubectl := func(args ...string) (string, error) {
+		if args[1] == "clusterversion" {
+			if strings.Contains(args[len(args)-1], "enabledCapabilities") {
+				return "baremetal marketplace", nil
+			}
+			return "baremetal marketplace openshift-samples Console", nil
+		}
+		out, ok := responses[args[1]]
+		if !ok {
+			return "", fmt.Errorf("unexpected arguments %v", args)
+		}
+		return out, nil
+	}
+
+	want := &clusterCapabilities{
+		IPStack:              "dual",
+		Topology:             "SingleReplica",
+		Architectures:        []string{"amd64", "arm64"},
+		Proxy:                true,
+		Disconnected:         true,
+		FIPS:                 true,
+		FeatureSet:           "Default",
+		DisabledCapabilities: []string{"Console", "openshift-samples"},
+	}
+	got := discoverClusterCapabilities(kubectl)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("discoverClusterCapabilities() = %#v, want %#v", got, want)
+	}
+
+	renamer := newGinkgoTestRenamerFromGlobals("", nil)
+	renamer.capabilitySkips = got.excludes
+	tests := map[string]string{
+		"[sig-node] NODE Author:minmli-High-41579-drain a worker [Skipped:SNO]":                             "[Skipped:SNO]",
+		"[sig-networking] SDN Author:zzhao-High-25321-egress [Skipped:DualStack]":                           "[Skipped:DualStack]",
+		"[sig-networking] SDN Author:zzhao-High-25322-single stack only [Skipped:ipv4]":                     "",
+		"[sig-mco] MCO Author:rioliu-High-42361-kernel arguments [Skipped:arm64]":                           "[Skipped:arm64]",
+		"[sig-operators] OLM NonPreRelease-ConnectedOnly-Author:jiazha-High-46964-Disable Copied CSVs":      "ConnectedOnly-",
+		"[sig-auth] AUTH Author:xxia-Medium-10000-console login [Skipped:NoCapability/Console]":             "[Skipped:NoCapability/Console]",
+		"[sig-api-machinery] API_Server Author:kewang-High-41664-tech preview apis [Skipped:NoTechPreview]": "[Skipped:NoTechPreview]",
+		"[sig-node] NODE Author:minmli-High-41580-runs everywhere":                                          "",
+	}
+	for name, want := range tests {
+		if got := renamer.clusterSkipReason(name); got != want {
+			t.Errorf("clusterSkipReason(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDiscoverClusterCapabilities_unreachable(t *testing.T) {
+	c := discoverClusterCapabilities(func(args ...string) (string, error) {
+		return "", fmt.Errorf("connection refused")
+	})
+	if excludes := c.excludes(); len(excludes) != 0 {
+		t.Errorf("expected no excludes for an unreachable cluster, got %v", excludes)
+	}
+}
+
+func TestClusterSkipReason_detectsCapabilitiesOnce(t *testing.T) {
+	var detected int
+	renamer := newGinkgoTestRenamerFromGlobals("", nil)
+	renamer.capabilitySkips = func() []string {
+		detected++
+		return (&clusterCapabilities{Topology: "SingleReplica"}).excludes()
+	}
+	renamer.maybeRenameTest("[sig-node] NODE Author:minmli-High-41579-drain a worker [Skipped:SNO]", &testNode{text: "[sig-node] NODE Author:minmli-High-41579-drain a worker [Skipped:SNO]"})
+	if detected != 0 {
+		t.Fatalf("expected naming the tests not to detect the cluster capabilities")
+	}
+	for i := 0; i < 2; i++ {
+		if got := renamer.clusterSkipReason("[sig-node] NODE Author:minmli-High-41579-drain a worker [Skipped:SNO]"); got != "[Skipped:SNO]" {
+			t.Errorf("clusterSkipReason() = %q, want %q", got, "[Skipped:SNO]")
+		}
+	}
+	if detected != 1 {
+		t.Errorf("expected the cluster capabilities to be detected once, got %d", detected)
+	}
+}
@@ -62,6 +62,8 @@ import (
 	securityv1client "github.com/openshift/client-go/security/clientset/versioned"
 	templatev1client "github.com/openshift/client-go/template/clientset/versioned"
 	userv1client "github.com/openshift/client-go/user/clientset/versioned"
+
+	"github.com/openshift/openshift-tests-private/test/extended/util/diagnostics"
 )
 
 // CLI provides function to call the OpenShift CLI and Kubernetes and OpenShift
@@ -309,6 +311,11 @@ func (c *CLI) TeardownProject() {
 	if len(c.Namespace()) > 0 && g.CurrentGinkgoTestDescription().Failed && e2e.TestContext.DumpLogsOnFailure {
 		e2e.DumpAllNamespaceInfo(c.kubeFramework.ClientSet, c.Namespace())
 	}
+	if dir := os.Getenv(diagnostics.DirEnv); len(dir) > 0 && len(c.Namespace()) > 0 && g.CurrentGinkgoTestDescription().Failed {
+		if err := WriteNamespaceDiagnostics(c, c.Namespace(), dir); err != nil {
+			e2e.Logf("Unable to write diagnostics for namespace %s: %v", c.Namespace(), err)
+		}
+	}
 
 	if len(c.configPath) > 0 {
 		os.Remove(c.configPath)
@@ -0,0 +1,9 @@
+// Package diagnostics holds what the test runner and the tests share about the
+// diagnostic bundles of failed tests. It has no dependencies so that the runner
+// does not import the test utilities.
+package diagnostics
+
+// DirEnv is set for each test to the directory its diagnostic bundle is written to
+// if it fails. The test writes the state of its namespaces there and the runner
+// adds the cluster operator conditions and monitor intervals.
+const DirEnv = "TEST_DIAGNOSTICS_DIR"
@@ -32,17 +32,54 @@ type TestData struct {
 // test is being executed. Description is used to populate the JUnit suite name, and testname is
 // used to define the overall test that will be run.
 func Run(description, testname string, adapter TestData, invariants []upgrades.Test, fn func()) {
+	RunSteps(description, testname, adapter, invariants, []Step{{Run: fn}})
+}
+
+// Step is a part of the disruptive action of RunSteps.
+type Step struct {
+	// Name is appended to the test name to name the test case of the step.
+	Name string
+	Run  func()
+}
+
+// RunSteps executes the provided steps in order while the invariants are checked,
+// and reports each step as its own test case. The steps after a failed step are
+// reported as skipped.
+func RunSteps(description, testname string, adapter TestData, invariants []upgrades.Test, steps []Step) {
 	testSuite := &junit.TestSuite{Name: description, Package: testname}
-	test := &junit.TestCase{Name: testname, Classname: testname}
-	testSuite.TestCases = append(testSuite.TestCases, test)
+	var tests []*junit.TestCase
+	for _, step := range steps {
+		name := testname
+		if len(step.Name) > 0 {
+			name = fmt.Sprintf("%s %s", testname, step.Name)
+		}
+		test := &junit.TestCase{Name: name, Classname: testname}
+		testSuite.TestCases = append(testSuite.TestCases, test)
+		tests = append(tests, test)
+	}
 	cm := chaosmonkey.New(func() {
-		start := time.Now()
-		defer finalizeTest(start, test)
-		fn()
+		for i, step := range steps {
+			if !runStep(step.Run, tests[i]) {
+				for _, test := range tests[i+1:] {
+					test.Skipped = fmt.Sprintf("%s did not pass", tests[i].Name)
+				}
+				return
+			}
+		}
 	})
 	runChaosmonkey(cm, adapter, invariants, testSuite)
 }
 
+func runStep(fn func(), test *junit.TestCase) (passed bool) {
+	start := time.Now()
+	defer func() {
+		passed = len(test.Failures) == 0 && len(test.Errors) == 0 && len(test.Skipped) == 0
+	}()
+	defer finalizeTest(start, test)
+	fn()
+	return
+}
+
 func runChaosmonkey(
 	cm *chaosmonkey.Chaosmonkey,
 	testData TestData,
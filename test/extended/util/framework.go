@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/selection"
+	kutilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
@@ -479,22 +480,62 @@ func DumpPodLogs(pods []kapiv1.Pod, oc *CLI) {
 			e2e.Logf("Error retrieving description for pod %q: %v\n\n", pod.Name, err)
 		}
 
-		dumpContainer := func(container *kapiv1.Container) {
-			depOutput, err := oc.AsAdmin().Run("logs").WithoutNamespace().Args("pod/"+pod.Name, "-c", container.Name, "-n", pod.Namespace).Output()
+		forEachContainerLog(oc, pod, func(container string, log string, err error) {
 			if err == nil {
-				e2e.Logf("Log for pod %q/%q\n---->\n%s\n<----end of log for %[1]q/%[2]q\n", pod.Name, container.Name, depOutput)
+				e2e.Logf("Log for pod %q/%q\n---->\n%s\n<----end of log for %[1]q/%[2]q\n", pod.Name, container, log)
 			} else {
-				e2e.Logf("Error retrieving logs for pod %q/%q: %v\n\n", pod.Name, container.Name, err)
+				e2e.Logf("Error retrieving logs for pod %q/%q: %v\n\n", pod.Name, container, err)
 			}
-		}
+		})
+	}
+}
 
-		for _, c := range pod.Spec.InitContainers {
-			dumpContainer(&c)
+// forEachContainerLog retrieves the log of every init and regular container of pod
+// and passes it to fn.
+func forEachContainerLog(oc *CLI, pod kapiv1.Pod, fn func(container string, log string, err error)) {
+	containers := append(append([]kapiv1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		log, err := oc.AsAdmin().Run("logs").WithoutNamespace().Args("pod/"+pod.Name, "-c", container.Name, "-n", pod.Namespace).Output()
+		fn(container.Name, log, err)
+	}
+}
+
+// WriteNamespaceDiagnostics writes the events, the pod YAML and the container logs
+// of namespace below dir/namespaces/<namespace>, so they can be inspected after the
+// namespace has been deleted.
+func WriteNamespaceDiagnostics(oc *CLI, namespace, dir string) error {
+	dir = filepath.Join(dir, "namespaces", namespace)
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0755); err != nil {
+		return err
+	}
+	var errs []error
+	for _, resource := range []string{"events", "pods"} {
+		out, err := oc.AsAdmin().Run("get").WithoutNamespace().Args(resource, "-n", namespace, "-o", "yaml").Output()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to get %s: %v", resource, err))
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, resource+".yaml"), []byte(out), 0644); err != nil {
+			errs = append(errs, err)
 		}
-		for _, c := range pod.Spec.Containers {
-			dumpContainer(&c)
+	}
+	pods, err := oc.AdminKubeClient().CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("unable to list pods: %v", err))
+	} else {
+		for _, pod := range pods.Items {
+			forEachContainerLog(oc, pod, func(container string, log string, err error) {
+				if err != nil {
+					log = fmt.Sprintf("Error retrieving logs: %v\n", err)
+				}
+				name := fmt.Sprintf("%s_%s.log", pod.Name, container)
+				if err := ioutil.WriteFile(filepath.Join(dir, "logs", name), []byte(log), 0644); err != nil {
+					errs = append(errs, err)
+				}
+			})
 		}
 	}
+	return kutilerrors.NewAggregate(errs)
 }
 
 // DumpPodsCommand runs the provided command in every pod identified by selector in the provided namespace.
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/config"
@@ -140,10 +141,26 @@ func AnnotateTestSuite() {
 	}
 
 	testRenamer := newGinkgoTestRenamerFromGlobals(e2e.TestContext.Provider, getNetworkSkips())
+	testRenamer.capabilitySkips = func() []string { return getClusterCapabilities().excludes() }
+	annotatedRenamer = testRenamer
 
 	ginkgo.WalkTests(testRenamer.maybeRenameTest)
 }
 
+// annotatedRenamer is the renamer the test suite was last annotated with.
+var annotatedRenamer *ginkgoTestRenamer
+
+// ClusterSkipReason returns the label or title prefix of the named test that keeps
+// it from running on the cluster the test suite was annotated against, or an empty
+// string if the test can run there. The capabilities of the cluster are detected on
+// the first call, so listing the tests does not need a cluster.
+func ClusterSkipReason(name string) string {
+	if annotatedRenamer == nil {
+		return ""
+	}
+	return annotatedRenamer.clusterSkipReason(name)
+}
+
 func getNetworkSkips() []string {
 	out, err := e2e.KubectlCmd("get", "network.operator.openshift.io", "cluster", "--template", "{{.spec.defaultNetwork.type}}{{if .spec.defaultNetwork.openshiftSDNConfig}} {{.spec.defaultNetwork.type}}/{{.spec.defaultNetwork.openshiftSDNConfig.mode}}{{end}}").CombinedOutput()
 	if err != nil {
@@ -153,6 +170,8 @@ func getNetworkSkips() []string {
 	return strings.Split(string(out), " ")
 }
 
+// newGinkgoTestRenamerFromGlobals returns a renamer that excludes the tests matching
+// the provider and network skips from the conformance suites.
 func newGinkgoTestRenamerFromGlobals(provider string, networkSkips []string) *ginkgoTestRenamer {
 	var allLabels []string
 	matches := make(map[string]*regexp.Regexp)
@@ -181,14 +200,20 @@ func newGinkgoTestRenamerFromGlobals(provider string, networkSkips []string) *gi
 	}
 	sort.Strings(allLabels)
 
+	var clusterExcludes []string
 	if provider != "" {
-		excludedTests = append(excludedTests, fmt.Sprintf(`\[Skipped:%s\]`, provider))
+		clusterExcludes = append(clusterExcludes, fmt.Sprintf(`\[Skipped:%s\]`, provider))
 	}
 	for _, network := range networkSkips {
-		excludedTests = append(excludedTests, fmt.Sprintf(`\[Skipped:Network/%s\]`, network))
+		clusterExcludes = append(clusterExcludes, fmt.Sprintf(`\[Skipped:Network/%s\]`, network))
 	}
+	excludedTests = append(excludedTests, clusterExcludes...)
 	klog.V(4).Infof("openshift-tests-private excluded test regex is %q", strings.Join(excludedTests, `|`))
 	excludedTestsFilter := regexp.MustCompile(strings.Join(excludedTests, `|`))
+	var clusterExcludesFilter *regexp.Regexp
+	if len(clusterExcludes) > 0 {
+		clusterExcludesFilter = regexp.MustCompile(strings.Join(clusterExcludes, `|`))
+	}
 
 	return &ginkgoTestRenamer{
 		allLabels:     allLabels,
@@ -196,7 +221,8 @@ func newGinkgoTestRenamerFromGlobals(provider string, networkSkips []string) *gi
 		matches:       matches,
 		excludes:      excludes,
 
-		excludedTestsFilter: excludedTestsFilter,
+		excludedTestsFilter:   excludedTestsFilter,
+		clusterExcludesFilter: clusterExcludesFilter,
 	}
 }
 
@@ -207,6 +233,34 @@ type ginkgoTestRenamer struct {
 	excludes      map[string]*regexp.Regexp
 
 	excludedTestsFilter *regexp.Regexp
+	// clusterExcludesFilter matches the tests that cannot run on the cluster
+	clusterExcludesFilter *regexp.Regexp
+
+	// capabilitySkips returns the patterns of the tests the capabilities of the cluster
+	// exclude. It is only called once a test is checked against the cluster.
+	capabilitySkips          func() []string
+	capabilityOnce           sync.Once
+	capabilityExcludesFilter *regexp.Regexp
+}
+
+func (r *ginkgoTestRenamer) clusterSkipReason(name string) string {
+	if r.clusterExcludesFilter != nil {
+		if reason := r.clusterExcludesFilter.FindString(name); len(reason) > 0 {
+			return reason
+		}
+	}
+	r.capabilityOnce.Do(func() {
+		if r.capabilitySkips == nil {
+			return
+		}
+		if skips := r.capabilitySkips(); len(skips) > 0 {
+			r.capabilityExcludesFilter = regexp.MustCompile(strings.Join(skips, `|`))
+		}
+	})
+	if r.capabilityExcludesFilter == nil {
+		return ""
+	}
+	return r.capabilityExcludesFilter.FindString(name)
 }
 
 func (r *ginkgoTestRenamer) maybeRenameTest(name string, node types.TestNode) {
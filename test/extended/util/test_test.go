@@ -76,11 +76,14 @@ func TestStockRules(t *testing.T) {
 	tests := []struct {
 		name string
 
-		testName string
-		provider string
-		netSkips []string
+		testName     string
+		provider     string
+		netSkips     []string
+		capabilities *clusterCapabilities
 
 		expectedText string
+		// expectedSkip is the reason the test cannot run on the cluster, if any
+		expectedSkip string
 	}{
 		{
 			name:         "not skipped",
@@ -94,18 +97,37 @@ func TestStockRules(t *testing.T) {
 			provider:     "gce",
 			testName:     `[sig-storage] In-tree Volumes [Driver: local][LocalVolumeType: gce-localssd-scsi-fs] [Serial] [Testpattern: Dynamic PV (default fs)] subPath should be able to unmount after the subpath directory is deleted`,
 			expectedText: `[sig-storage] In-tree Volumes [Driver: local][LocalVolumeType: gce-localssd-scsi-fs] [Serial] [Testpattern: Dynamic PV (default fs)] subPath should be able to unmount after the subpath directory is deleted [Skipped:gce]`, // notice that this isn't categorized into any of our buckets
+			expectedSkip: `[Skipped:gce]`,
 		},
 		{
 			name:         "should skip NetworkPolicy tests on multitenant",
 			netSkips:     []string{"OpenShiftSdn", "OpenShiftSdn/Multitenant"},
 			testName:     `[Feature:NetworkPolicy] should do something with NetworkPolicy`,
 			expectedText: `[Feature:NetworkPolicy] should do something with NetworkPolicy [Skipped:Network/OpenShiftSdn/Multitenant]`,
+			expectedSkip: `[Skipped:Network/OpenShiftSdn/Multitenant]`,
+		},
+		{
+			name:         "should skip SNO tests on single node clusters",
+			capabilities: &clusterCapabilities{Topology: "SingleReplica"},
+			testName:     `[sig-node] NODE Author:minmli-High-41579-drain a worker [Skipped:SNO]`,
+			expectedText: `[sig-node] NODE Author:minmli-High-41579-drain a worker [Skipped:SNO] [Suite:openshift/conformance/parallel]`,
+			expectedSkip: `[Skipped:SNO]`,
+		},
+		{
+			name:         "should skip connected only tests on disconnected clusters",
+			capabilities: &clusterCapabilities{Disconnected: true},
+			testName:     `[sig-updates] OTA cvo ConnectedOnly-Author:yanyang-Medium-43178-manage channel [Serial]`,
+			expectedText: `[sig-updates] OTA cvo ConnectedOnly-Author:yanyang-Medium-43178-manage channel [Serial] [Suite:openshift/conformance/serial]`,
+			expectedSkip: `ConnectedOnly-`,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			testRenamer := newGinkgoTestRenamerFromGlobals(test.provider, test.netSkips)
+			if test.capabilities != nil {
+				testRenamer.capabilitySkips = test.capabilities.excludes
+			}
 			testNode := &testNode{
 				text: test.testName,
 			}
@@ -115,7 +137,10 @@ func TestStockRules(t *testing.T) {
 			if e, a := test.expectedText, testNode.Text(); e != a {
 				t.Error(a)
 			}
-
+			// the capabilities of the cluster do not change the name, only exclude the test
+			if e, a := test.expectedSkip, testRenamer.clusterSkipReason(testNode.Text()); e != a {
+				t.Errorf("clusterSkipReason() = %q, want %q", a, e)
+			}
 		})
 	}
 }
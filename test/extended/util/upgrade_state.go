@@ -0,0 +1,56 @@
+package util
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openshift/openshift-tests-private/test/extended/util/upgradestate"
+)
+
+// upgradeState returns the store shared by the running upgrade check and its other
+// half, and the key of the check that scopes the values.
+func upgradeState() (*upgradestate.Store, string, error) {
+	dir := os.Getenv(upgradestate.DirEnv)
+	if len(dir) == 0 {
+		return nil, "", fmt.Errorf("%s is not set, upgrade checks must be run by run-upgrade", upgradestate.DirEnv)
+	}
+	check := os.Getenv(upgradestate.CheckKeyEnv)
+	if len(check) == 0 {
+		return nil, "", fmt.Errorf("%s is not set, the running test is not an upgrade check with a case ID", upgradestate.CheckKeyEnv)
+	}
+	return upgradestate.NewStore(dir), check, nil
+}
+
+// UpgradeStateEnabled returns true if the upgrade checks were run with a store to
+// pass state through, which is the case when the cluster upgrade is run along with
+// them. Checks that are also run on their own must work without it.
+func UpgradeStateEnabled() bool {
+	return len(os.Getenv(upgradestate.DirEnv)) > 0
+}
+
+// SetUpgradeState records a value in a [PreChkUpgrade] test for the [PstChkUpgrade]
+// test with the same first case ID to read after the upgrade.
+func SetUpgradeState(key, value string) error {
+	store, check, err := upgradeState()
+	if err != nil {
+		return err
+	}
+	return store.Set(check, key, value)
+}
+
+// GetUpgradeState returns a value recorded by the [PreChkUpgrade] test paired with
+// the running [PstChkUpgrade] test. It fails if the value was never recorded.
+func GetUpgradeState(key string) (string, error) {
+	store, check, err := upgradeState()
+	if err != nil {
+		return "", err
+	}
+	value, ok, err := store.Get(check, key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("upgrade state %q of %s was not recorded before the upgrade", key, check)
+	}
+	return value, nil
+}
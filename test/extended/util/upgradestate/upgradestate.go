@@ -0,0 +1,91 @@
+// Package upgradestate is the store through which a pre-upgrade check passes state
+// to its post-upgrade check. It is shared by the test runner and the tests, and
+// has no dependencies so that the runner does not import the test utilities.
+package upgradestate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DirEnv is the directory of the store. It is kept if already set, so that the
+	// checks can be run by separate invocations.
+	DirEnv = "TEST_UPGRADE_STATE_DIR"
+	// CheckKeyEnv is set by run-test to the key of the running upgrade check.
+	CheckKeyEnv = "TEST_UPGRADE_CHECK_KEY"
+)
+
+// CheckKey returns the key that pairs a pre-upgrade check with its post-upgrade
+// check, the sig and the first case ID of the test. The sig may be empty.
+func CheckKey(sig, caseID string) string {
+	if len(sig) == 0 {
+		return caseID
+	}
+	return sig + "-" + caseID
+}
+
+// Store is a key/value store on disk. Values are scoped by the key of the check
+// that pairs them.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+func (s *Store) path(check, key string) (string, error) {
+	if !keyPattern.MatchString(check) || !keyPattern.MatchString(key) || strings.HasPrefix(check, ".") || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("upgrade state key %q of check %q may only contain letters, digits, '_', '.' and '-'", key, check)
+	}
+	return filepath.Join(s.dir, check, key), nil
+}
+
+// Set records the value of key for the check, replacing any previous value.
+func (s *Store) Set(check, key, value string) error {
+	path, err := s.path(check, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first so a reader never sees a partial value
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get returns the value of key for the check, and false if it was never set.
+func (s *Store) Get(check, key string) (string, bool, error) {
+	path, err := s.path(check, key)
+	if err != nil {
+		return "", false, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
@@ -0,0 +1,39 @@
+package upgradestate
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upgrade-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewStore(dir)
+	if _, ok, err := store.Get("sig-isc-39254", "aide-pod"); ok || err != nil {
+		t.Fatalf("expected an unset value, got %t %v", ok, err)
+	}
+	for _, value := range []string{"aide-worker-0", "aide-worker-1"} {
+		if err := store.Set("sig-isc-39254", "aide-pod", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if value, ok, err := NewStore(dir).Get("sig-isc-39254", "aide-pod"); value != "aide-worker-1" || !ok || err != nil {
+		t.Errorf("unexpected value %q %t %v", value, ok, err)
+	}
+	if _, ok, _ := store.Get("sig-isc-45014", "aide-pod"); ok {
+		t.Errorf("expected values to be scoped by check")
+	}
+	for _, key := range []string{"../escape", ".hidden", "a/b", ""} {
+		if err := store.Set("sig-isc-39254", key, "value"); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+	if err := store.Set("..", "key", "value"); err == nil {
+		t.Errorf("expected check %q to be rejected", "..")
+	}
+}